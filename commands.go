@@ -0,0 +1,1187 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vistrcm/pmoclient/gdocs"
+	"github.com/vistrcm/pmoclient/pmo"
+)
+
+// selection defines which engineers commands work with
+type selection struct {
+	spreadsheet bool      // use names from spreadsheet as filter
+	all         bool      // do not filter engineers by names
+	where       string    // filter expression, overrides `where` from config
+	strict      bool      // fail if some names match nobody
+	asOf        dateValue // show assignments active on the date only
+	everyone    bool      // select everyone from profiles without filterUsers unless spreadsheet is used
+}
+
+// dateValue is flag value with date in YYYY-MM-DD format
+type dateValue struct {
+	time.Time
+}
+
+func (d *dateValue) String() string {
+	return pmo.FormatDate(d.Time)
+}
+
+// Set parses date
+func (d *dateValue) Set(value string) error {
+	t, err := time.Parse(pmo.DateLayout, value)
+	if err != nil {
+		return fmt.Errorf("date must be in YYYY-MM-DD format")
+	}
+	d.Time = t
+	return nil
+}
+
+// output defines how command results are written
+type output struct {
+	format   string
+	columns  string
+	sort     string
+	template string
+	options  pmo.OutputOptions
+}
+
+// outputFlags registers flags to select output format, columns and sort order
+func outputFlags(out *output) func(fs *flag.FlagSet) {
+	return func(fs *flag.FlagSet) {
+		fs.StringVar(&out.format, "o", pmo.OutputTable, "output format: "+strings.Join(pmo.OutputFormats, ", "))
+		fs.StringVar(&out.columns, "columns", "", "comma-separated columns for table, csv, tsv and markdown output: "+
+			strings.Join(pmo.ColumnNames(), ", "))
+		fs.StringVar(&out.sort, "sort", "", "comma-separated sort keys. Prefix column with '-' or add ':desc' for descending order, "+
+			"e.g. location,-daysOnBench. Default is location")
+		fs.StringVar(&out.template, "template", "", "Go text/template executed against list of engineers instead of -o format. "+
+			"Use @path to read template from file")
+	}
+}
+
+// check parses output flags. Returns usage error if they are not valid.
+func (out *output) check() error {
+	out.options = pmo.OutputOptions{Format: out.format}
+	if err := checkFormat(out.format); err != nil {
+		return err
+	}
+
+	var err error
+	if out.columns != "" {
+		if out.options.Columns, err = pmo.ParseColumns(out.columns); err != nil {
+			return &usageError{message: err.Error()}
+		}
+	}
+	if out.sort != "" {
+		if out.options.Sort, err = pmo.ParseSortKeys(out.sort); err != nil {
+			return &usageError{message: err.Error()}
+		}
+	}
+	if out.template != "" {
+		text := out.template
+		if strings.HasPrefix(text, "@") {
+			raw, err := ioutil.ReadFile(text[1:]) // nolint: gosec
+			if err != nil {
+				return fmt.Errorf("can not read template: %w", err)
+			}
+			text = string(raw)
+		}
+		if out.options.Template, err = pmo.ParseTemplate(text); err != nil {
+			return &usageError{message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// combineFlags returns function registering all flags
+func combineFlags(flags ...func(fs *flag.FlagSet)) func(fs *flag.FlagSet) {
+	return func(fs *flag.FlagSet) {
+		for _, f := range flags {
+			f(fs)
+		}
+	}
+}
+
+// checkFormat returns usage error if output format is unknown
+func checkFormat(format string) error {
+	for _, known := range pmo.OutputFormats {
+		if format == known {
+			return nil
+		}
+	}
+	return usageErrorf("unknown output format %q, use one of %s", format, strings.Join(pmo.OutputFormats, ", "))
+}
+
+// report defines how report is written: to stdout in format and optionally to sheet tab
+type report struct {
+	format string
+	sheet  bool
+	tab    string
+}
+
+// reportFlags registers flags to select report format and to write report to spreadsheet tab
+func reportFlags(rep *report, tab string) func(fs *flag.FlagSet) {
+	return func(fs *flag.FlagSet) {
+		rep.tab = tab
+		fs.StringVar(&rep.format, "o", pmo.OutputTable, "output format: "+strings.Join(pmo.OutputFormats, ", "))
+		fs.BoolVar(&rep.sheet, "sheet", false, fmt.Sprintf("also write report to '%s' sheet of the spreadsheet", tab))
+	}
+}
+
+// write writes report records in machine formats or tables in table-like formats, and tables to the sheet tab
+func (rep *report) write(ctx context.Context, records interface{}, tables ...pmo.Table) error {
+	if err := pmo.WriteReport(os.Stdout, rep.format, records, tables...); err != nil {
+		return err
+	}
+	return rep.writeSheet(ctx, tables...)
+}
+
+// writeSheet writes tables to the sheet tab if selected
+func (rep *report) writeSheet(ctx context.Context, tables ...pmo.Table) error {
+	if !rep.sheet {
+		return nil
+	}
+	es, err := openSpreadsheet(ctx)
+	if err != nil {
+		return err
+	}
+	return es.WriteTables(ctx, rep.tab, tables...)
+}
+
+// monthValue is flag value with month in YYYY-MM format
+type monthValue struct {
+	time.Time
+}
+
+func (m *monthValue) String() string {
+	if m.IsZero() {
+		return ""
+	}
+	return m.Format(pmo.MonthLayout)
+}
+
+// Set parses month
+func (m *monthValue) Set(value string) error {
+	t, err := time.Parse(pmo.MonthLayout, value)
+	if err != nil {
+		return fmt.Errorf("month must be in YYYY-MM format")
+	}
+	m.Time = t
+	return nil
+}
+
+// selectionFlags registers flags to select engineers
+func selectionFlags(sel *selection) func(fs *flag.FlagSet) {
+	return func(fs *flag.FlagSet) {
+		fs.BoolVar(&sel.spreadsheet, "spreadsheet", false, "use names from spreadsheet instead of filterUsers from config")
+		fs.BoolVar(&sel.all, "all", false, "use all engineers, do not filter by names")
+		filterFlags(sel)(fs)
+	}
+}
+
+// filterFlags registers flags with filter expression and strict mode
+func filterFlags(sel *selection) func(fs *flag.FlagSet) {
+	return func(fs *flag.FlagSet) {
+		fs.StringVar(&sel.where, "where", "", `filter expression, e.g. 'location=="Kyiv" && grade in ["SE3","SE4"] && daysOnBench>10'. `+
+			"Overrides where from config")
+		fs.BoolVar(&sel.strict, "strict", false, "exit with error if some of names match nobody in PMO")
+		asOfFlag(sel)(fs)
+	}
+}
+
+// asOfFlag registers flag with date to show assignments for
+func asOfFlag(sel *selection) func(fs *flag.FlagSet) {
+	return func(fs *flag.FlagSet) {
+		fs.Var(&sel.asOf, "as-of", "date in YYYY-MM-DD format. Show only assignments active on the date, "+
+			"accounts, projects and statuses are computed for the date too")
+	}
+}
+
+func rootCommand() *command {
+	var sel selection
+	var out output
+	return &command{
+		name: "pmoclient",
+		description: "pmoclient gets information about engineers from PMO. " +
+			"Without command prints table of engineers; with -spreadsheet also updates the spreadsheet.",
+		flags: combineFlags(func(fs *flag.FlagSet) {
+			fs.BoolVar(&sel.spreadsheet, "spreadsheet", false, "use spreadsheet to get names and update spreadsheet at the end")
+		}, filterFlags(&sel), outputFlags(&out)),
+		run: func(ctx context.Context, args []string) error {
+			if err := out.check(); err != nil {
+				return err
+			}
+			if sel.spreadsheet {
+				return sheetPush(ctx, sel, &out)
+			}
+			return peopleList(ctx, sel, &out)
+		},
+		subcommands: []*command{
+			peopleCommand(),
+			groupCommand("accounts", "Account", "Lists accounts with engineers working on them.", (*pmo.Person).GetAccounts),
+			groupCommand("projects", "Project", "Lists projects with engineers assigned to them.", (*pmo.Person).GetProjects),
+			benchCommand(),
+			rolloffCommand(),
+			allocationsCommand(),
+			capacityCommand(),
+			snapshotCommand(),
+			diffCommand(),
+			sheetCommand(),
+			loginCommand(),
+			logoutCommand(),
+			configCommand(),
+		},
+	}
+}
+
+func peopleCommand() *command {
+	var sel, showSel selection
+	var listOut, showOut output
+	return &command{
+		name:        "people",
+		description: "Shows information about engineers.",
+		subcommands: []*command{
+			{
+				name:        "list",
+				description: "Prints table of engineers selected by filterUsers from config or by names from spreadsheet.",
+				flags:       combineFlags(selectionFlags(&sel), outputFlags(&listOut)),
+				run: func(ctx context.Context, args []string) error {
+					return peopleList(ctx, sel, &listOut)
+				},
+			},
+			{
+				name:        "show",
+				args:        "<name>",
+				description: "Prints all information about engineer including assignments. Name may be ID, username, alias or name; case, diacritics, spaces and order of words in name are ignored.",
+				flags:       combineFlags(asOfFlag(&showSel), outputFlags(&showOut)),
+				run: func(ctx context.Context, args []string) error {
+					return peopleShow(ctx, args, showSel, &showOut)
+				},
+			},
+		},
+	}
+}
+
+func peopleList(ctx context.Context, sel selection, out *output) error {
+	if err := out.check(); err != nil {
+		return err
+	}
+	engineers, err := loadEngineers(ctx, sel)
+	if err != nil {
+		return err
+	}
+	return pmo.WriteEngineers(os.Stdout, engineers, out.options)
+}
+
+func peopleShow(ctx context.Context, args []string, sel selection, out *output) error {
+	if len(args) == 0 {
+		return usageErrorf("engineer name is required")
+	}
+	if err := out.check(); err != nil {
+		return err
+	}
+	name := strings.Join(args, " ")
+	engineers, err := loadEngineers(ctx, selection{all: true, asOf: sel.asOf})
+	if err != nil {
+		return err
+	}
+	_, configs, err := loadConfigs()
+	if err != nil {
+		return err
+	}
+	var aliases []map[string]string
+	for _, config := range configs {
+		aliases = append(aliases, config.Aliases)
+	}
+	found := pmo.FindByName(engineers, name, mergeAliases(aliases...))
+	if len(found) == 0 {
+		return fmt.Errorf("engineer %q not found", name)
+	}
+	if out.format != pmo.OutputTable || out.options.Template != nil {
+		return pmo.WriteEngineers(os.Stdout, found, out.options)
+	}
+	for i, engineer := range found {
+		if i > 0 {
+			fmt.Println()
+		}
+		if err := pmo.PrintPerson(os.Stdout, engineer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupCommand creates command to print engineers grouped by keys
+func groupCommand(name string, title string, description string, keys func(*pmo.Person) []string) *command {
+	var sel selection
+	return &command{
+		name:        name,
+		description: description,
+		flags:       selectionFlags(&sel),
+		run: func(ctx context.Context, args []string) error {
+			engineers, err := loadEngineers(ctx, sel)
+			if err != nil {
+				return err
+			}
+			groups := pmo.GroupBy(engineers, func(p pmo.Person) []string { return keys(&p) })
+			return pmo.PrintGroups(os.Stdout, title, groups)
+		},
+	}
+}
+
+func benchCommand() *command {
+	sel := selection{everyone: true}
+	var rep report
+	var below float64
+	var longBench int
+	return &command{
+		name: "bench",
+		description: "Lists engineers with total involvement below threshold, longest on bench first, with totals " +
+			"by location, grade and profile. -as-of sets the date to compute involvement for, default is today.",
+		flags: combineFlags(selectionFlags(&sel), reportFlags(&rep, "Bench"), func(fs *flag.FlagSet) {
+			fs.Float64Var(&below, "below", 100, "list engineers with total involvement below this percent")
+			fs.IntVar(&longBench, "long", 30, "flag engineers on bench longer than this number of days")
+		}),
+		run: func(ctx context.Context, args []string) error {
+			if err := checkFormat(rep.format); err != nil {
+				return err
+			}
+			date := sel.asOf.Time
+			if date.IsZero() {
+				date = time.Now()
+			}
+			// last account may be out of the date, so engineers keep all assignments
+			sel.asOf = dateValue{}
+			engineers, err := loadEngineers(ctx, sel)
+			if err != nil {
+				return err
+			}
+			bench := pmo.Bench(engineers, date, below, longBench)
+			return rep.write(ctx, bench, bench.Tables()...)
+		},
+	}
+}
+
+func rolloffCommand() *command {
+	sel := selection{everyone: true}
+	var rep report
+	var within string
+	return &command{
+		name: "rolloff",
+		description: "Lists engineers whose current assignments end within period and who have nothing lined up right after. " +
+			"Shows ending assignment, next assignment if any and gap in days. -as-of sets the date to count from, default is today.",
+		flags: combineFlags(selectionFlags(&sel), reportFlags(&rep, "Rolloff"), func(fs *flag.FlagSet) {
+			fs.StringVar(&within, "within", "30d", "period to look ahead, e.g. 30d, 2w or 72h")
+		}),
+		run: func(ctx context.Context, args []string) error {
+			if err := checkFormat(rep.format); err != nil {
+				return err
+			}
+			period, err := pmo.ParsePeriod(within)
+			if err != nil {
+				return &usageError{message: err.Error()}
+			}
+			date := sel.asOf.Time
+			if date.IsZero() {
+				date = time.Now()
+			}
+			// next assignments are needed, so engineers keep all assignments
+			sel.asOf = dateValue{}
+			engineers, err := loadEngineers(ctx, sel)
+			if err != nil {
+				return err
+			}
+			rolloffs := pmo.Rolloffs(engineers, date, period)
+			return rep.write(ctx, rolloffs, pmo.RolloffTable(rolloffs))
+		},
+	}
+}
+
+func allocationsCommand() *command {
+	var sel selection
+	var rep report
+	var rules pmo.AllocationRules
+	var statuses string
+	return &command{
+		name:        "allocations",
+		description: "Works with engineers allocations.",
+		subcommands: []*command{
+			{
+				name: "check",
+				description: "Finds concurrent assignments over 100% involvement, gaps between assignments, " +
+					"assignments finishing before start and unknown statuses. Exits with error if anything is found.",
+				flags: combineFlags(selectionFlags(&sel), reportFlags(&rep, "Allocations"), func(fs *flag.FlagSet) {
+					fs.IntVar(&rules.MaxGap, "gap", 14, "report gaps between assignments longer than this number of days")
+					fs.StringVar(&statuses, "statuses", strings.Join(pmo.DefaultStatuses, ","), "comma-separated known assignment statuses")
+				}),
+				run: func(ctx context.Context, args []string) error {
+					if err := checkFormat(rep.format); err != nil {
+						return err
+					}
+					rules.Statuses = nil
+					for _, status := range strings.Split(statuses, ",") {
+						rules.Statuses = append(rules.Statuses, strings.TrimSpace(status))
+					}
+					engineers, err := loadEngineers(ctx, sel)
+					if err != nil {
+						return err
+					}
+					findings := pmo.CheckAllocations(engineers, rules)
+					if err := rep.write(ctx, findings, pmo.FindingsTable(findings)); err != nil {
+						return err
+					}
+					if len(findings) > 0 {
+						return fmt.Errorf("%w: %d", pmo.ErrAllocations, len(findings))
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func capacityCommand() *command {
+	sel := selection{everyone: true}
+	var rep report
+	var from, to monthValue
+	var statuses string
+	return &command{
+		name: "capacity",
+		description: "Sums involvement of active assignments into FTE per account and project with heads per grade and location. " +
+			"-as-of sets the date, default is today. With -from shows the numbers month by month.",
+		flags: combineFlags(selectionFlags(&sel), reportFlags(&rep, "Capacity"), func(fs *flag.FlagSet) {
+			fs.Var(&from, "from", "first month of monthly report in YYYY-MM format")
+			fs.Var(&to, "to", "last month of monthly report in YYYY-MM format. Default is -from")
+			fs.StringVar(&statuses, "statuses", strings.Join(pmo.DefaultCapacityStatuses, ","),
+				"comma-separated statuses of assignments counted into FTE")
+		}),
+		run: func(ctx context.Context, args []string) error {
+			if err := checkFormat(rep.format); err != nil {
+				return err
+			}
+			if from.IsZero() && !to.IsZero() {
+				return usageErrorf("-to requires -from")
+			}
+			if to.IsZero() {
+				to = from
+			}
+			if to.Before(from.Time) {
+				return usageErrorf("-to is before -from")
+			}
+			date := sel.asOf.Time
+			if date.IsZero() {
+				date = time.Now()
+			}
+			// assignments are selected by the report period
+			sel.asOf = dateValue{}
+			engineers, err := loadEngineers(ctx, sel)
+			if err != nil {
+				return err
+			}
+
+			counted := strings.Split(statuses, ",")
+			if from.IsZero() {
+				rows := pmo.Capacity(engineers, date, counted)
+				return rep.write(ctx, rows, pmo.CapacityTable(rows))
+			}
+			rows := pmo.MonthlyCapacity(engineers, from.Time, to.Time, counted)
+			pivot, details := pmo.CapacityPivot(rows, from.Time, to.Time), pmo.CapacityTable(rows)
+			pivot.Title, details.Title = "FTE by month", "Details"
+			return rep.write(ctx, rows, pivot, details)
+		},
+	}
+}
+
+func snapshotCommand() *command {
+	var out output
+	var keep int
+	var olderThan string
+	return &command{
+		name: "snapshot",
+		description: "Works with snapshots of PMO people list. Every list fetched from PMO is saved as snapshot; " +
+			"use -snapshot flag to run any command from a snapshot.",
+		subcommands: []*command{
+			{
+				name:        "list",
+				description: "Lists snapshots of the selected profiles, oldest first.",
+				run: func(ctx context.Context, args []string) error {
+					return snapshotList()
+				},
+			},
+			{
+				name:        "save",
+				description: "Fetches people list from PMO and saves snapshot.",
+				run: func(ctx context.Context, args []string) error {
+					opts.refresh = true
+					_, configs, err := loadConfigs()
+					if err != nil {
+						return err
+					}
+					for _, config := range configs {
+						if _, err := engineersOf(ctx, config); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			},
+			{
+				name:        "load",
+				args:        "<snapshot>",
+				description: "Prints engineers from snapshot: snapshot ID, 'latest' or date in YYYY-MM-DD format.",
+				flags:       outputFlags(&out),
+				run: func(ctx context.Context, args []string) error {
+					if len(args) != 1 {
+						return usageErrorf("snapshot is required")
+					}
+					if err := out.check(); err != nil {
+						return err
+					}
+					opts.snapshot = args[0]
+					engineers, err := loadEngineers(ctx, selection{all: true})
+					if err != nil {
+						return err
+					}
+					return pmo.WriteEngineers(os.Stdout, engineers, out.options)
+				},
+			},
+			{
+				name:        "prune",
+				description: "Removes old snapshots of the selected profiles.",
+				flags: func(fs *flag.FlagSet) {
+					fs.IntVar(&keep, "keep", 10, "number of the latest snapshots of every profile to keep")
+					fs.StringVar(&olderThan, "older-than", "", "remove only snapshots older than period, e.g. 30d or 8w")
+				},
+				run: func(ctx context.Context, args []string) error {
+					var before time.Time
+					if olderThan != "" {
+						period, err := pmo.ParsePeriod(olderThan)
+						if err != nil {
+							return &usageError{message: err.Error()}
+						}
+						before = time.Now().Add(-period)
+					}
+					return snapshotPrune(keep, before)
+				},
+			},
+		},
+	}
+}
+
+func diffCommand() *command {
+	var rep report
+	var where string
+	var below float64
+	return &command{
+		name: "diff",
+		args: "<from> <to>",
+		description: "Shows what changed between two snapshots: added and removed engineers, changes of grade, " +
+			"profile, position, location and manager, new, ended and changed assignments and bench transitions. " +
+			"Snapshot is snapshot ID, 'latest' or date in YYYY-MM-DD format.",
+		flags: func(fs *flag.FlagSet) {
+			rep.tab = "Diff"
+			fs.StringVar(&rep.format, "o", outputChangelog, "output format: "+outputChangelog+", "+
+				strings.Join(pmo.OutputFormats, ", "))
+			fs.BoolVar(&rep.sheet, "sheet", false, fmt.Sprintf("also write changes to '%s' sheet of the spreadsheet", rep.tab))
+			fs.StringVar(&where, "where", "", "compare only engineers matching filter expression, see README")
+			fs.Float64Var(&below, "below", 100, "engineer is on bench with total involvement below this percent")
+		},
+		run: func(ctx context.Context, args []string) error {
+			if len(args) != 2 {
+				return usageErrorf("two snapshots are required")
+			}
+			if rep.format != outputChangelog {
+				if err := checkFormat(rep.format); err != nil {
+					return err
+				}
+			}
+			changes, title, err := diffSnapshots(args[0], args[1], where, below)
+			if err != nil {
+				return err
+			}
+			if rep.format != outputChangelog {
+				return rep.write(ctx, changes, pmo.ChangesTable(changes))
+			}
+			if err := pmo.WriteChangelog(os.Stdout, title, changes); err != nil {
+				return err
+			}
+			return rep.writeSheet(ctx, pmo.ChangesTable(changes))
+		},
+	}
+}
+
+// outputChangelog is output format of diff command with changes grouped by kind
+const outputChangelog = "changelog"
+
+// diffSnapshots returns changes between snapshots of selected profiles referenced by from and to,
+// and changelog title with compared snapshot IDs. Only engineers matching where expression, or `where` from config
+// if expression is empty, in either snapshot are compared.
+func diffSnapshots(from string, to string, where string, below float64) ([]pmo.Change, string, error) {
+	_, configs, err := loadConfigs()
+	if err != nil {
+		return nil, "", err
+	}
+	var wherePredicate pmo.Predicate
+	if where != "" {
+		if wherePredicate, err = pmo.ParseFilter(where); err != nil {
+			return nil, "", &usageError{message: err.Error()}
+		}
+	}
+
+	changes := []pmo.Change{}
+	var compared []string
+	for _, config := range configs {
+		predicate := wherePredicate
+		if predicate == nil && config.Where != "" {
+			if predicate, err = pmo.ParseFilter(config.Where); err != nil {
+				return nil, "", err
+			}
+		}
+		var sides [2]pmo.DiffSide
+		var ids [2]string
+		for i, ref := range []string{from, to} {
+			snapshot, err := loadSnapshot(config, ref)
+			if err != nil {
+				return nil, "", err
+			}
+			sides[i] = pmo.DiffSide{Engineers: snapshot.Response.Data, Time: snapshot.Time}
+			ids[i] = snapshot.Time.Format("2006-01-02 15:04") + " UTC"
+		}
+		found := pmo.Diff(sides[0], sides[1], below, predicate)
+		if len(configs) > 1 {
+			for i := range found {
+				found[i].Source = config.Name
+			}
+		}
+		changes = append(changes, found...)
+		period := fmt.Sprintf("from %s to %s", ids[0], ids[1])
+		if len(configs) > 1 {
+			period = fmt.Sprintf("of %s %s", config.Name, period)
+		}
+		compared = append(compared, period)
+	}
+	return changes, "Changes " + strings.Join(compared, "; "), nil
+}
+
+// snapshotList prints snapshots of selected profiles
+func snapshotList() error {
+	_, configs, err := loadConfigs()
+	if err != nil {
+		return err
+	}
+	table := pmo.Table{Header: []string{"ID", "Time", "Profile", "Size"}}
+	for _, config := range configs {
+		store, err := pmo.NewSnapshotStore(config.SnapshotDir)
+		if err != nil {
+			return err
+		}
+		snapshots, err := store.List(pmo.SnapshotProfile(config.Name))
+		if err != nil {
+			return err
+		}
+		for _, info := range snapshots {
+			table.Rows = append(table.Rows, []interface{}{info.ID, info.Time.Local().Format("2006-01-02 15:04:05"),
+				info.Profile, info.Size})
+		}
+	}
+	return pmo.WriteReport(os.Stdout, pmo.OutputTable, nil, table)
+}
+
+// snapshotPrune removes snapshots of selected profiles
+func snapshotPrune(keep int, before time.Time) error {
+	_, configs, err := loadConfigs()
+	if err != nil {
+		return err
+	}
+	for _, config := range configs {
+		store, err := pmo.NewSnapshotStore(config.SnapshotDir)
+		if err != nil {
+			return err
+		}
+		removed, err := store.Prune(pmo.SnapshotProfile(config.Name), keep, before)
+		if err != nil {
+			return err
+		}
+		log.Printf("removed %d snapshots of profile %q from %s", len(removed), pmo.SnapshotProfile(config.Name), store.Dir())
+	}
+	return nil
+}
+
+func sheetCommand() *command {
+	var pushSel selection
+	return &command{
+		name:        "sheet",
+		description: "Works with engineers spreadsheet defined in Spreadsheet section of config.",
+		subcommands: []*command{
+			{
+				name:        "pull-names",
+				description: "Prints names of engineers from the spreadsheet, one per line.",
+				run: func(ctx context.Context, args []string) error {
+					_, names, _, err := openSheet(ctx)
+					if err != nil {
+						return err
+					}
+					for _, name := range names {
+						fmt.Println(name)
+					}
+					return nil
+				},
+			},
+			{
+				name:        "push",
+				description: "Gets engineers listed in the spreadsheet from PMO and writes them to 'AutofillFromPMO' sheet.",
+				flags:       filterFlags(&pushSel),
+				run: func(ctx context.Context, args []string) error {
+					return sheetPush(ctx, pushSel, nil)
+				},
+			},
+		},
+	}
+}
+
+// sheetPush updates spreadsheet with engineers listed in it. Prints engineers if out is set.
+func sheetPush(ctx context.Context, sel selection, out *output) error {
+	es, names, aliases, err := openSheet(ctx)
+	if err != nil {
+		return err
+	}
+	engineers, unmatched, err := fetchEngineers(ctx, func(pmo.Configuration) []string { return names }, aliases, sel)
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		if err := pmo.WriteEngineers(os.Stdout, engineers, out.options); err != nil {
+			return err
+		}
+	}
+	if err := es.Clear(ctx); err != nil {
+		return err
+	}
+	if err := es.AppendEngineers(ctx, engineers); err != nil {
+		return err
+	}
+	if len(unmatched) > 0 {
+		if err := es.AppendUnmatched(ctx, unmatched); err != nil {
+			return err
+		}
+	}
+	return sel.checkUnmatched(unmatched)
+}
+
+func loginCommand() *command {
+	return &command{
+		name:        "login",
+		description: "Logs in to PMO and saves session. Useful to check credentials.",
+		run: func(ctx context.Context, args []string) error {
+			_, configs, err := loadConfigs()
+			if err != nil {
+				return err
+			}
+			for _, config := range configs {
+				p := newPMO(config)
+				if err := p.Login(ctx); err != nil {
+					return err
+				}
+				log.Printf("logged in to %s", config.LoginURL)
+			}
+			return nil
+		},
+	}
+}
+
+func logoutCommand() *command {
+	return &command{
+		name:        "logout",
+		description: "Removes saved PMO sessions of all profiles.",
+		run: func(ctx context.Context, args []string) error {
+			return pmo.RemoveSessions()
+		},
+	}
+}
+
+func configCommand() *command {
+	var spreadsheet bool
+	return &command{
+		name:        "config",
+		description: "Prints effective configuration and source of every value.",
+		run: func(ctx context.Context, args []string) error {
+			config, sources, err := pmo.LoadConfig(opts.configPath, opts.profile)
+			if err != nil {
+				return err
+			}
+			return pmo.PrintConfig(os.Stdout, config, sources)
+		},
+		subcommands: []*command{
+			{
+				name:        "validate",
+				description: "Checks config file and all profiles and reports every problem.",
+				flags: func(fs *flag.FlagSet) {
+					fs.BoolVar(&spreadsheet, "spreadsheet", false, "check spreadsheet settings too")
+				},
+				run: func(ctx context.Context, args []string) error {
+					warnings, err := pmo.ValidateConfig(opts.configPath, spreadsheet)
+					for _, warning := range warnings {
+						log.Printf("[WARN] %s", warning)
+					}
+					if err != nil {
+						return err
+					}
+					log.Println("config is valid")
+					return nil
+				},
+			},
+			{
+				name:        "convert",
+				args:        "json|yaml|toml",
+				description: "Prints config file translated to another format. Comments are not preserved.",
+				run: func(ctx context.Context, args []string) error {
+					if len(args) != 1 {
+						return usageErrorf("target format is required: json, yaml or toml")
+					}
+					return convertConfig(opts.configPath, args[0])
+				},
+			},
+		},
+	}
+}
+
+// convertConfig prints config file translated to format
+func convertConfig(configPath string, format string) error {
+	path, _ := pmo.ConfigPath(configPath)
+	from, err := pmo.ConfigFormat(path)
+	if err != nil {
+		return err
+	}
+	raw, err := ioutil.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return err
+	}
+	converted, err := pmo.ConvertConfig(raw, from, format)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(converted)
+	return err
+}
+
+// loadConfigs returns configuration of the selected profile followed by profiles to merge.
+// Selected profile is used for spreadsheet settings.
+func loadConfigs() (pmo.Configuration, []pmo.Configuration, error) {
+	config, _, err := pmo.LoadConfig(opts.configPath, opts.profile)
+	if err != nil {
+		return config, nil, err
+	}
+	configs := []pmo.Configuration{config}
+	if opts.profiles != "" {
+		var names []string
+		if opts.profiles != "all" {
+			names = strings.Split(opts.profiles, ",")
+		}
+		if configs, err = pmo.LoadProfiles(opts.configPath, names); err != nil {
+			return config, nil, err
+		}
+	}
+
+	for _, profileConfig := range configs {
+		if err := profileConfig.Validate(false); err != nil {
+			return config, nil, err
+		}
+	}
+	return config, configs, nil
+}
+
+// openSheet opens spreadsheet of the selected profile and returns names listed in it
+func openSheet(ctx context.Context) (gdocs.EngineersSheet, []string, map[string]string, error) {
+	es, err := openSpreadsheet(ctx)
+	if err != nil {
+		return es, nil, nil, err
+	}
+	names, aliases, err := es.GetNames(ctx)
+	return es, names, aliases, err
+}
+
+// openSpreadsheet opens spreadsheet of the selected profile
+func openSpreadsheet(ctx context.Context) (gdocs.EngineersSheet, error) {
+	config, _, err := pmo.LoadConfig(opts.configPath, opts.profile)
+	if err != nil {
+		return gdocs.EngineersSheet{}, err
+	}
+	if err := config.Validate(true); err != nil {
+		return gdocs.EngineersSheet{}, err
+	}
+	return gdocs.NewEngineersSheet(ctx, config.Spreadsheet.SpreadsheetID, config.Spreadsheet.SecretFile)
+}
+
+// loadEngineers returns engineers selected by sel from all configured profiles
+func loadEngineers(ctx context.Context, sel selection) ([]pmo.Person, error) {
+	var names func(pmo.Configuration) []string
+	var aliases map[string]string
+	switch {
+	case sel.all:
+	case sel.spreadsheet:
+		_, sheetNames, sheetAliases, err := openSheet(ctx)
+		if err != nil {
+			return nil, err
+		}
+		names = func(pmo.Configuration) []string { return sheetNames }
+		aliases = sheetAliases
+	default:
+		names = func(config pmo.Configuration) []string { return config.FilterUsers }
+	}
+	engineers, unmatched, err := fetchEngineers(ctx, names, aliases, sel)
+	if err != nil {
+		return nil, err
+	}
+	return engineers, sel.checkUnmatched(unmatched)
+}
+
+// checkUnmatched returns error for unmatched names in strict mode
+func (sel selection) checkUnmatched(unmatched []pmo.Unmatched) error {
+	if !sel.strict || len(unmatched) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(unmatched))
+	for _, u := range unmatched {
+		names = append(names, u.Name)
+	}
+	return fmt.Errorf("%w: %s", pmo.ErrUnmatched, strings.Join(names, ", "))
+}
+
+// fetchEngineers gets engineers from every selected profile. If names is not nil only
+// engineers matching names returned for the profile are returned, unless sel.everyone is set and
+// the profile has no filterUsers. Aliases extend aliases from config.
+// If date is selected, engineers have only assignments active on the date.
+// Engineers are filtered by where expression, or by `where` from config if expression is empty.
+// Names which match nobody in every profile or several engineers are reported to stderr and returned.
+func fetchEngineers(ctx context.Context, names func(pmo.Configuration) []string, aliases map[string]string,
+	sel selection) ([]pmo.Person, []pmo.Unmatched, error) {
+	_, configs, err := loadConfigs()
+	if err != nil {
+		return nil, nil, err
+	}
+	var wherePredicate pmo.Predicate
+	if sel.where != "" {
+		if wherePredicate, err = pmo.ParseFilter(sel.where); err != nil {
+			return nil, nil, &usageError{message: err.Error()}
+		}
+	}
+
+	var engineers, everyone []pmo.Person
+	var entries []string                        // names in order of appearance
+	resolved := make(map[string]bool)           // name matches somebody in at least one profile
+	ambiguous := make(map[string]pmo.Unmatched) // name matches several engineers in some profile
+	for _, config := range configs {
+		var predicates []pmo.Predicate
+		switch {
+		case wherePredicate != nil:
+			predicates = append(predicates, wherePredicate)
+		case config.Where != "":
+			predicate, err := pmo.ParseFilter(config.Where)
+			if err != nil {
+				return nil, nil, err
+			}
+			predicates = append(predicates, predicate)
+		}
+
+		all, err := engineersOf(ctx, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		selected := all
+		if names != nil && !(sel.everyone && !sel.spreadsheet && len(config.FilterUsers) == 0) {
+			var problems []pmo.Unmatched
+			selected, problems = pmo.NewMatcher(all, mergeAliases(config.Aliases, aliases)).Match(names(config))
+			failed := make(map[string]pmo.Unmatched)
+			for _, u := range problems {
+				failed[u.Name] = u
+			}
+			for _, name := range pmo.RemoveDuplicates(names(config)) {
+				if _, ok := resolved[name]; !ok {
+					entries = append(entries, name)
+				}
+				u, ok := failed[name]
+				if u.Ambiguous {
+					ambiguous[name] = u
+				}
+				resolved[name] = resolved[name] || !ok
+			}
+			everyone = append(everyone, all...)
+		}
+		if !sel.asOf.IsZero() {
+			for i := range selected {
+				selected[i] = selected[i].AsOf(sel.asOf.Time)
+			}
+		}
+		found := pmo.Select(selected, pmo.And(predicates...))
+
+		if len(configs) > 1 {
+			for i := range found {
+				found[i].Source = config.Name
+			}
+		}
+		engineers = append(engineers, found...)
+	}
+
+	var unmatched []pmo.Unmatched
+	for _, name := range entries {
+		u, ok := ambiguous[name]
+		switch {
+		case ok:
+		case !resolved[name]:
+			u = pmo.Unmatched{Name: name, Suggestions: pmo.Suggest(everyone, name)}
+		default:
+			continue
+		}
+		log.Print(u)
+		unmatched = append(unmatched, u)
+	}
+	return engineers, unmatched, nil
+}
+
+// engineersOf returns all engineers of profile from snapshot if selected, from cache if it is fresh
+// or offline mode is selected, or from PMO. Fetched people list is cached and saved as snapshot.
+func engineersOf(ctx context.Context, config pmo.Configuration) ([]pmo.Person, error) {
+	if opts.snapshot != "" {
+		snapshot, err := loadSnapshot(config, opts.snapshot)
+		if err != nil {
+			return nil, err
+		}
+		return snapshot.Response.Data, nil
+	}
+	if opts.offline && opts.refresh {
+		return nil, usageErrorf("-offline and -refresh can not be used together")
+	}
+
+	cache, err := peopleCache(config)
+	if err != nil {
+		return nil, err
+	}
+	if opts.offline {
+		engineers, err := cachedEngineers(config, cache, true)
+		if err != nil {
+			return nil, fmt.Errorf("%w. Run without -offline to fetch people list or use -snapshot latest", err)
+		}
+		return engineers, nil
+	}
+	if !opts.refresh {
+		// missing or stale cache is not an error, people list is fetched from PMO
+		if engineers, err := cachedEngineers(config, cache, false); err == nil {
+			return engineers, nil
+		}
+	}
+
+	p := newPMO(config)
+	// saved session is verified by the first request and renewed if rejected
+	if !p.HasSession() {
+		if err := p.Login(ctx); err != nil {
+			return nil, err
+		}
+	}
+	response, err := p.PeopleList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if invalid := pmo.InvalidAssignments(response.Data); invalid > 0 {
+		log.Printf("%d assignments have dates or involvement in unknown format, run 'allocations check' to list them",
+			invalid)
+	}
+	savePeopleList(config, cache, response, time.Now())
+	return response.Data, nil
+}
+
+// peopleCache returns people list cache of config or nil if cache directory is not available
+func peopleCache(config pmo.Configuration) (*pmo.PeopleCache, error) {
+	ttl, err := config.CacheDuration()
+	if err != nil {
+		return nil, err
+	}
+	cacheFile, err := pmo.DefaultCacheFile(config)
+	if err != nil {
+		log.Printf("people list is not cached: %v", err)
+		return nil, nil
+	}
+	return pmo.NewPeopleCache(cacheFile, config.PeopleListURL, ttl), nil
+}
+
+// cachedEngineers returns engineers from cache and reports cache age to log
+func cachedEngineers(config pmo.Configuration, cache *pmo.PeopleCache, anyAge bool) ([]pmo.Person, error) {
+	if cache == nil {
+		return nil, fmt.Errorf("%w: cache is not enabled", pmo.ErrNoCache)
+	}
+	response, fetched, err := cache.Load(anyAge)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("using people list%s cached %s ago at %s", profileSuffix(config.Name),
+		pmo.FormatAge(time.Since(fetched)), fetched.Local().Format("2006-01-02 15:04"))
+	return response.Data, nil
+}
+
+// savePeopleList caches people list fetched at t and saves it as snapshot. Report does not fail without them,
+// so errors are only logged.
+func savePeopleList(config pmo.Configuration, cache *pmo.PeopleCache, response pmo.APIResponse, t time.Time) {
+	if cache != nil {
+		if err := cache.Save(response, t); err != nil {
+			log.Printf("people list is not cached: %v", err)
+		}
+	}
+	// snapshots are optional, live commands work without data directory
+	store, err := pmo.NewSnapshotStore(config.SnapshotDir)
+	if err != nil {
+		log.Printf("snapshots are disabled: %v", err)
+		return
+	}
+	if _, err := store.Save(config.Name, response, t); err != nil {
+		log.Printf("snapshot is not saved: %v", err)
+	}
+}
+
+// profileSuffix returns ` of profile "name"` for named profile
+func profileSuffix(profile string) string {
+	if profile == "" {
+		return ""
+	}
+	return fmt.Sprintf(" of profile %q", profile)
+}
+
+// loadSnapshot loads snapshot of profile referenced by ref
+func loadSnapshot(config pmo.Configuration, ref string) (pmo.Snapshot, error) {
+	store, err := pmo.NewSnapshotStore(config.SnapshotDir)
+	if err != nil {
+		return pmo.Snapshot{}, err
+	}
+	info, err := store.Find(ref, config.Name)
+	if err != nil {
+		return pmo.Snapshot{}, err
+	}
+	return store.Load(info.ID)
+}
+
+// mergeAliases returns aliases from all maps. Later maps override earlier ones.
+func mergeAliases(maps ...map[string]string) map[string]string {
+	result := make(map[string]string)
+	for _, aliases := range maps {
+		for alias, target := range aliases {
+			result[alias] = target
+		}
+	}
+	return result
+}
+
+// newPMO creates PMO client for config with saved session if session can be saved
+func newPMO(config pmo.Configuration) *pmo.PMO {
+	p := pmo.NewPMO(config)
+	// without cache directory session is kept in memory for this run only
+	if sessionFile, err := pmo.DefaultSessionFile(config.Name); err != nil {
+		log.Printf("session is not saved between runs: %v", err)
+	} else if err := p.UseSessionFile(sessionFile); err != nil {
+		log.Printf("ignoring saved session: %v", err)
+	}
+	return &p
+}
@@ -0,0 +1,12 @@
+package gdocs
+
+import "errors"
+
+// Errors returned by the gdocs package. They are wrapped with additional
+// context, so use errors.Is to check for them.
+var (
+	// ErrAuth is returned when google client can not be authorized.
+	ErrAuth = errors.New("gdocs: authorization failed")
+	// ErrSheets is returned when Sheets API request fails.
+	ErrSheets = errors.New("gdocs: sheets request failed")
+)
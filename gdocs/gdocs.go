@@ -26,23 +26,32 @@ type EngineersSheet struct {
 	cleanRange    string
 }
 
-// GetNames return names defined in spreadsheet
-func (es *EngineersSheet) GetNames() []string {
-	resp, err := es.srv.Spreadsheets.Values.Get(es.spreadsheetID, es.namesRange).Do()
+// GetNames return names defined in spreadsheet and aliases: optional PMO ID, username or name
+// in the second column for names which do not match PMO.
+func (es *EngineersSheet) GetNames(ctx context.Context) ([]string, map[string]string, error) {
+	resp, err := es.srv.Spreadsheets.Values.Get(es.spreadsheetID, es.namesRange).Context(ctx).Do()
 	if err != nil {
-		log.Fatalf("Unable to retrieve data from sheet: %v", err)
+		return nil, nil, fmt.Errorf("%w: unable to retrieve data from sheet: %v", ErrSheets, err)
 	}
 
 	var result []string
-	if len(resp.Values) == 0 {
-		fmt.Println("No data found.")
-	} else {
-		for _, row := range resp.Values {
-			name := row[0].(string)
-			result = append(result, name)
+	aliases := make(map[string]string)
+	for _, row := range resp.Values {
+		if len(row) == 0 {
+			continue
+		}
+		name, ok := row[0].(string)
+		if !ok || name == "" {
+			continue
+		}
+		result = append(result, name)
+		if len(row) > 1 {
+			if target := strings.TrimSpace(fmt.Sprint(row[1])); target != "" {
+				aliases[name] = target
+			}
 		}
 	}
-	return result
+	return result, aliases, nil
 }
 
 func slice2str(data []string) string {
@@ -50,17 +59,18 @@ func slice2str(data []string) string {
 }
 
 // Clear spreadsheet defined in spreadsheetID
-func (es *EngineersSheet) Clear() {
+func (es *EngineersSheet) Clear(ctx context.Context) error {
 	var vr sheets.ClearValuesRequest
 
-	_, err := es.srv.Spreadsheets.Values.Clear(es.spreadsheetID, es.cleanRange, &vr).Do()
+	_, err := es.srv.Spreadsheets.Values.Clear(es.spreadsheetID, es.cleanRange, &vr).Context(ctx).Do()
 	if err != nil {
-		log.Fatalf("Unable to clear data from sheet: %v\n", err)
+		return fmt.Errorf("%w: unable to clear data from sheet: %v", ErrSheets, err)
 	}
+	return nil
 }
 
 // AppendEngineers from engineers slice to the spreadsheet
-func (es *EngineersSheet) AppendEngineers(engineers []pmo.Person) {
+func (es *EngineersSheet) AppendEngineers(ctx context.Context, engineers []pmo.Person) error {
 	// add header
 	values := []interface{}{
 		"Name",
@@ -80,23 +90,100 @@ func (es *EngineersSheet) AppendEngineers(engineers []pmo.Person) {
 		"ID",
 		"ServiceLine",
 		"InBusinessTrip",
+		"Source",
 	}
 
 	var vr sheets.ValueRange
 	vr.Values = append(vr.Values, values)
 
-	_, err := es.srv.Spreadsheets.Values.Append(es.spreadsheetID, es.appendRange, &vr).ValueInputOption("RAW").Do()
+	_, err := es.srv.Spreadsheets.Values.Append(es.spreadsheetID, es.appendRange, &vr).ValueInputOption("RAW").Context(ctx).Do()
 	if err != nil {
-		log.Fatalf("Unable to update data from sheet: %v", err)
+		return fmt.Errorf("%w: unable to append header to sheet: %v", ErrSheets, err)
 	}
 
 	for _, engineer := range engineers {
-		es.appendEngineer(engineer)
+		if err := es.appendEngineer(ctx, engineer); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// AppendUnmatched appends filter entries not found in PMO or ambiguous with candidate names after engineers
+func (es *EngineersSheet) AppendUnmatched(ctx context.Context, unmatched []pmo.Unmatched) error {
+	var vr sheets.ValueRange
+	vr.Values = append(vr.Values, []interface{}{}, []interface{}{"Unresolved name", "Problem", "Candidates"})
+	for _, u := range unmatched {
+		problem := "not found"
+		if u.Ambiguous {
+			problem = "ambiguous"
+		}
+		vr.Values = append(vr.Values, []interface{}{u.Name, problem, strings.Join(u.Suggestions, "\n")})
+	}
+
+	_, err := es.srv.Spreadsheets.Values.Append(es.spreadsheetID, es.appendRange, &vr).ValueInputOption("RAW").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("%w: unable to append unmatched names to sheet: %v", ErrSheets, err)
+	}
+	return nil
+}
+
+// WriteTables replaces content of sheet tab with tables separated by empty row.
+// Tab is created if it does not exist.
+func (es *EngineersSheet) WriteTables(ctx context.Context, tab string, tables ...pmo.Table) error {
+	if err := es.ensureTab(ctx, tab); err != nil {
+		return err
+	}
+	var cr sheets.ClearValuesRequest
+	if _, err := es.srv.Spreadsheets.Values.Clear(es.spreadsheetID, tab, &cr).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("%w: unable to clear %q sheet: %v", ErrSheets, tab, err)
+	}
+
+	var vr sheets.ValueRange
+	for i, table := range tables {
+		if i > 0 {
+			vr.Values = append(vr.Values, []interface{}{})
+		}
+		if len(tables) > 1 && table.Title != "" {
+			vr.Values = append(vr.Values, []interface{}{table.Title})
+		}
+		header := make([]interface{}, 0, len(table.Header))
+		for _, title := range table.Header {
+			header = append(header, title)
+		}
+		vr.Values = append(vr.Values, header)
+		vr.Values = append(vr.Values, table.Rows...)
+	}
+	_, err := es.srv.Spreadsheets.Values.Update(es.spreadsheetID, tab+"!A1", &vr).ValueInputOption("RAW").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("%w: unable to write %q sheet: %v", ErrSheets, tab, err)
+	}
+	return nil
+}
+
+// ensureTab adds sheet tab to spreadsheet if it does not exist
+func (es *EngineersSheet) ensureTab(ctx context.Context, tab string) error {
+	spreadsheet, err := es.srv.Spreadsheets.Get(es.spreadsheetID).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("%w: unable to retrieve spreadsheet: %v", ErrSheets, err)
+	}
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties != nil && sheet.Properties.Title == tab {
+			return nil
+		}
+	}
+
+	request := sheets.BatchUpdateSpreadsheetRequest{Requests: []*sheets.Request{
+		{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: tab}}},
+	}}
+	if _, err := es.srv.Spreadsheets.BatchUpdate(es.spreadsheetID, &request).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("%w: unable to add %q sheet: %v", ErrSheets, tab, err)
+	}
+	return nil
 }
 
 // appendEngineer to append  Person to the spreadsheet.
-func (es *EngineersSheet) appendEngineer(engineer pmo.Person) {
+func (es *EngineersSheet) appendEngineer(ctx context.Context, engineer pmo.Person) error {
 	values := []interface{}{
 		engineer.Name,
 		engineer.Location,
@@ -115,105 +202,115 @@ func (es *EngineersSheet) appendEngineer(engineer pmo.Person) {
 		engineer.ID,
 		engineer.ServiceLine,
 		engineer.InBusinessTrip,
+		engineer.Source,
 	}
 
 	var vr sheets.ValueRange
 	vr.Values = append(vr.Values, values)
 
-	_, err := es.srv.Spreadsheets.Values.Append(es.spreadsheetID, es.appendRange, &vr).ValueInputOption("RAW").Do()
+	_, err := es.srv.Spreadsheets.Values.Append(es.spreadsheetID, es.appendRange, &vr).ValueInputOption("RAW").Context(ctx).Do()
 	if err != nil {
-		log.Fatalf("Unable to update data from sheet: %v", err)
+		return fmt.Errorf("%w: unable to append %q to sheet: %v", ErrSheets, engineer.Name, err)
 	}
+	return nil
 }
 
 // NewEngineersSheet generates new
-func NewEngineersSheet(spreadsheetID string, secretFile string) EngineersSheet {
-	client := clientFromFile(secretFile)
+func NewEngineersSheet(ctx context.Context, spreadsheetID string, secretFile string) (EngineersSheet, error) {
+	client, err := clientFromFile(ctx, secretFile)
+	if err != nil {
+		return EngineersSheet{}, err
+	}
 	srv, err := sheets.New(client)
 	if err != nil {
-		log.Fatalf("unable to retrieve Sheets client: %v", err)
+		return EngineersSheet{}, fmt.Errorf("%w: unable to retrieve Sheets client: %v", ErrSheets, err)
 	}
 
 	// do some work
 	es := EngineersSheet{
 		srv:           srv,
 		spreadsheetID: spreadsheetID,
-		namesRange:    "list!A2:A100",
+		namesRange:    "list!A2:B100",
 		appendRange:   "AutofillFromPMO!A1",
 		cleanRange:    "AutofillFromPMO!A1:ZZ1000",
 	}
-	return es
+	return es, nil
 }
 
-func clientFromFile(secretFile string) *http.Client {
+func clientFromFile(ctx context.Context, secretFile string) (*http.Client, error) {
 	b, err := ioutil.ReadFile(secretFile) // nolint: gosec
 	if err != nil {
-		log.Fatalf("Unable to read client secret file: %v", err)
+		return nil, fmt.Errorf("%w: unable to read client secret file: %v", ErrAuth, err)
 	}
 	// If modifying these scopes, delete your previously saved gdoc_client_secret.json
 	config, err := google.ConfigFromJSON(b, "https://www.googleapis.com/auth/spreadsheets")
 	if err != nil {
-		log.Fatalf("unable to parse client secret file to config: %v", err)
+		return nil, fmt.Errorf("%w: unable to parse client secret file to config: %v", ErrAuth, err)
 	}
-	client := getClient(config)
-	return client
+	return getClient(ctx, config)
 }
 
 // getClient retrieves a token, saves the token, then returns the generated client
-func getClient(config *oauth2.Config) *http.Client {
+func getClient(ctx context.Context, config *oauth2.Config) (*http.Client, error) {
 	usr, err := user.Current()
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("%w: can not detect current user: %v", ErrAuth, err)
 	}
 	tokFile := usr.HomeDir + "/.config/pmoclient_gdoc_token.json"
 	tok, err := tokenFromFile(tokFile)
 	if err != nil {
-		tok = getTokenFromWeb(config)
-		saveToken(tokFile, tok)
+		tok, err = getTokenFromWeb(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		if err := saveToken(tokFile, tok); err != nil {
+			return nil, err
+		}
 	}
-	return config.Client(context.Background(), tok)
+	return config.Client(ctx, tok), nil
 }
 
 // Request a token from web, then returns the retrieved token.
-func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
+func getTokenFromWeb(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
 	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
 	fmt.Printf("Go to the following link in your browser then type the authorization code: \n%v\n", authURL)
 	var authCode string
 	if _, err := fmt.Scan(&authCode); err != nil {
-		log.Fatalf("Unable to read authorization code: %v", err)
+		return nil, fmt.Errorf("%w: unable to read authorization code: %v", ErrAuth, err)
 	}
 
-	tok, err := config.Exchange(context.TODO(), authCode)
+	tok, err := config.Exchange(ctx, authCode)
 	if err != nil {
-		log.Fatalf("Unable to retrieve token from web: %v", err)
+		return nil, fmt.Errorf("%w: unable to retrieve token from web: %v", ErrAuth, err)
 	}
-	return tok
+	return tok, nil
 }
 
 // Retrieves token from a local file.
 func tokenFromFile(file string) (*oauth2.Token, error) {
 	f, err := os.Open(file) // nolint: gosec
-	defer checkDefer(f.Close)
 	if err != nil {
 		return nil, err
 	}
+	defer checkDefer(f.Close)
 	tok := &oauth2.Token{}
 	err = json.NewDecoder(f).Decode(tok)
 	return tok, err
 }
 
 // Save a token to a file path.
-func saveToken(path string, token *oauth2.Token) {
+func saveToken(path string, token *oauth2.Token) error {
 	log.Printf("Saving credentials file to: %s\n", path)
 	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
-		log.Fatalf("Unable to cache oauth token: %v", err)
+		return fmt.Errorf("%w: unable to cache oauth token: %v", ErrAuth, err)
 	}
 	defer checkDefer(f.Close)
 	err = json.NewEncoder(f).Encode(token)
 	if err != nil {
-		log.Fatalf("unable to encode token: %v", err)
+		return fmt.Errorf("%w: unable to encode token: %v", ErrAuth, err)
 	}
+	return nil
 }
 
 // checkDefer helper to catch errors in deferred functions
@@ -1,13 +1,17 @@
 module github.com/vistrcm/pmoclient
 
-go 1.12
+go 1.13
 
 require (
 	cloud.google.com/go v0.27.0 // indirect
+	github.com/BurntSushi/toml v1.2.1
 	github.com/golang/protobuf v1.2.0 // indirect
 	golang.org/x/net v0.0.0-20180906233101-161cd47e91fd // indirect
 	golang.org/x/oauth2 v0.0.0-20180821212333-d2e6202438be
 	golang.org/x/sync v0.0.0-20190423024810-112230192c58 // indirect
+	golang.org/x/term v0.0.0-20201210144234-2321bbc49cbf
+	golang.org/x/text v0.3.6
 	google.golang.org/api v0.0.0-20180910000450-7ca32eb868bf
 	google.golang.org/appengine v1.1.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
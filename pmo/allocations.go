@@ -0,0 +1,204 @@
+package pmo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of allocation findings
+const (
+	FindingOverlap     = "overlap"
+	FindingGap         = "gap"
+	FindingDates       = "dates"
+	FindingInvolvement = "involvement"
+	FindingStatus      = "status"
+)
+
+// DefaultStatuses are assignment statuses considered known by CheckAllocations
+var DefaultStatuses = []string{"Active", "Proposed", "Planned", "Completed"}
+
+// Finding is problem with engineer assignments
+type Finding struct {
+	Name        string `json:"name"`
+	Username    string `json:"username"`
+	Source      string `json:"source,omitempty"`
+	Kind        string `json:"kind"`
+	Assignments []int  `json:"assignments"`
+	Details     string `json:"details"`
+}
+
+// AllocationRules configure CheckAllocations
+type AllocationRules struct {
+	// MaxGap is the longest allowed number of days without assignment between assignments
+	MaxGap int
+	// Statuses are known assignment statuses. Empty means DefaultStatuses.
+	Statuses []string
+}
+
+// CheckAllocations finds concurrent assignments with total involvement over 100%, gaps between assignments
+// longer than rules.MaxGap days, assignments finishing before start or with dates in unknown format,
+// assignments with involvement in unknown format and assignments with unknown status.
+func CheckAllocations(engineers []Person, rules AllocationRules) []Finding {
+	statuses := rules.Statuses
+	if len(statuses) == 0 {
+		statuses = DefaultStatuses
+	}
+	known := make(map[string]bool)
+	for _, status := range statuses {
+		known[strings.ToLower(status)] = true
+	}
+
+	findings := []Finding{}
+	for i := range engineers {
+		engineer := &engineers[i]
+		add := func(kind string, details string, assignments ...Assignment) {
+			ids := make([]int, 0, len(assignments))
+			for _, a := range assignments {
+				ids = append(ids, a.ID)
+			}
+			findings = append(findings, Finding{Name: engineer.Name, Username: engineer.Username, Source: engineer.Source,
+				Kind: kind, Assignments: ids, Details: details})
+		}
+
+		var valid []Assignment
+		for _, a := range engineer.Assignments {
+			if !known[strings.ToLower(a.Status)] {
+				add(FindingStatus, fmt.Sprintf("unknown status %q", a.Status), a)
+			}
+			if a.HasInvalidInvolvement() {
+				add(FindingInvolvement, fmt.Sprintf("unknown involvement format %q, counted as 0%%", a.RawInvolvement), a)
+			}
+			if a.HasInvalidDates() {
+				add(FindingDates, invalidDates(a), a)
+				continue
+			}
+			if !a.Start.IsZero() && !a.Finish.IsZero() && a.Finish.Before(a.Start) {
+				add(FindingDates, fmt.Sprintf("finish %s is before start %s", FormatDate(a.Finish), FormatDate(a.Start)), a)
+				continue
+			}
+			valid = append(valid, a)
+		}
+		for _, o := range overlaps(valid) {
+			add(FindingOverlap, fmt.Sprintf("%g%% involvement from %s", totalInvolvement(o.assignments), FormatDate(o.from)),
+				o.assignments...)
+		}
+		for _, g := range gaps(valid, rules.MaxGap) {
+			add(FindingGap, fmt.Sprintf("%d days without assignment from %s", g.days, FormatDate(g.from)),
+				g.before, g.after)
+		}
+	}
+	return findings
+}
+
+// invalidDates describes dates of assignment sent by PMO in unknown format
+func invalidDates(a Assignment) string {
+	var problems []string
+	if a.RawStart != "" {
+		problems = append(problems, fmt.Sprintf("unknown start date format %q", a.RawStart))
+	}
+	if a.RawFinish != "" {
+		problems = append(problems, fmt.Sprintf("unknown finish date format %q", a.RawFinish))
+	}
+	return strings.Join(problems, ", ")
+}
+
+// overlap is set of concurrent assignments
+type overlap struct {
+	from        time.Time
+	assignments []Assignment
+}
+
+// overlaps returns sets of assignments active at the same day with total involvement over 100%.
+// Every set is reported once from the first day it is active.
+func overlaps(assignments []Assignment) []overlap {
+	var days []time.Time
+	for _, a := range assignments {
+		days = append(days, a.Start)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	var result []overlap
+	seen := make(map[string]bool)
+	for _, day := range days {
+		var active []Assignment
+		for _, a := range assignments {
+			if a.ActiveOn(day) {
+				active = append(active, a)
+			}
+		}
+		key := assignmentIDs(active)
+		if totalInvolvement(active) <= 100 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, overlap{from: day, assignments: active})
+	}
+	return result
+}
+
+// gap is period without assignments
+type gap struct {
+	from          time.Time
+	days          int
+	before, after Assignment
+}
+
+// gaps returns periods between assignments longer than maxDays
+func gaps(assignments []Assignment, maxDays int) []gap {
+	sorted := make([]Assignment, len(assignments))
+	copy(sorted, assignments)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var result []gap
+	var last *Assignment // assignment covering the latest day so far
+	for i := range sorted {
+		a := sorted[i]
+		if last != nil && !last.Finish.IsZero() && !a.Start.IsZero() {
+			days := int(a.Start.Sub(last.Finish)/oneDay) - 1
+			if days > maxDays {
+				result = append(result, gap{from: last.Finish.Add(oneDay), days: days, before: *last, after: a})
+			}
+		}
+		if last == nil || finishesLater(a, *last) {
+			last = &sorted[i]
+		}
+	}
+	return result
+}
+
+// totalInvolvement sums involvement of assignments
+func totalInvolvement(assignments []Assignment) float64 {
+	total := 0.0
+	for _, a := range assignments {
+		total += a.Involvement
+	}
+	return total
+}
+
+// assignmentIDs returns comma-separated IDs of assignments
+func assignmentIDs(assignments []Assignment) string {
+	ids := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		ids = append(ids, strconv.Itoa(a.ID))
+	}
+	return strings.Join(ids, ",")
+}
+
+// FindingsTable returns findings as table
+func FindingsTable(findings []Finding) Table {
+	table := Table{Header: []string{"Name", "Username", "Kind", "Assignments", "Details"}}
+	sources := make([]string, 0, len(findings))
+	for _, f := range findings {
+		ids := make([]string, 0, len(f.Assignments))
+		for _, id := range f.Assignments {
+			ids = append(ids, strconv.Itoa(id))
+		}
+		table.Rows = append(table.Rows, []interface{}{f.Name, f.Username, f.Kind, strings.Join(ids, ","), f.Details})
+		sources = append(sources, f.Source)
+	}
+	table.addSourceColumn(sources)
+	return table
+}
@@ -0,0 +1,199 @@
+package pmo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// assignment returns assignment with id, dates in DateLayout and involvement. Empty date means open end.
+func assignment(id int, start string, finish string, involvement float64) Assignment {
+	a := Assignment{ID: id, Involvement: involvement, Status: "Active"}
+	if start != "" {
+		a.Start, _ = ParseDate(start)
+	}
+	if finish != "" {
+		a.Finish, _ = ParseDate(finish)
+	}
+	return a
+}
+
+func TestOverlaps(t *testing.T) {
+	type want struct {
+		from string
+		ids  string
+	}
+	tests := []struct {
+		name        string
+		assignments []Assignment
+		want        []want
+	}{
+		{"single", []Assignment{assignment(1, "2026-01-01", "2026-12-31", 100)}, nil},
+		{"sequential", []Assignment{
+			assignment(1, "2026-01-01", "2026-06-30", 100),
+			assignment(2, "2026-07-01", "2026-12-31", 100),
+		}, nil},
+		{"concurrent within 100%", []Assignment{
+			assignment(1, "2026-01-01", "2026-12-31", 50),
+			assignment(2, "2026-03-01", "2026-12-31", 50),
+		}, nil},
+		{"over 100%", []Assignment{
+			assignment(1, "2026-02-01", "2026-12-31", 60),
+			assignment(2, "2026-06-01", "2026-11-30", 60),
+		}, []want{{"2026-06-01", "1,2"}}},
+		{"touching on the last day", []Assignment{
+			assignment(1, "2026-01-01", "2026-06-30", 100),
+			assignment(2, "2026-06-30", "2026-12-31", 100),
+		}, []want{{"2026-06-30", "1,2"}}},
+		{"open end", []Assignment{
+			assignment(1, "2026-01-01", "", 100),
+			assignment(2, "2027-01-01", "2027-03-31", 20),
+		}, []want{{"2027-01-01", "1,2"}}},
+		{"different sets", []Assignment{
+			assignment(1, "2026-01-01", "2026-12-31", 80),
+			assignment(2, "2026-03-01", "2026-04-30", 40),
+			assignment(3, "2026-06-01", "2026-07-31", 40),
+		}, []want{{"2026-03-01", "1,2"}, {"2026-06-01", "1,3"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []want
+			for _, o := range overlaps(tt.assignments) {
+				got = append(got, want{FormatDate(o.from), assignmentIDs(o.assignments)})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("overlaps = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGaps(t *testing.T) {
+	type want struct {
+		from          string
+		days          int
+		before, after int
+	}
+	tests := []struct {
+		name        string
+		assignments []Assignment
+		maxDays     int
+		want        []want
+	}{
+		{"adjacent", []Assignment{
+			assignment(1, "2026-01-01", "2026-06-30", 100),
+			assignment(2, "2026-07-01", "2026-12-31", 100),
+		}, 0, nil},
+		{"gap within limit", []Assignment{
+			assignment(1, "2026-01-01", "2026-06-30", 100),
+			assignment(2, "2026-07-15", "2026-12-31", 100),
+		}, 14, nil},
+		{"gap over limit", []Assignment{
+			assignment(1, "2026-01-01", "2026-06-30", 100),
+			assignment(2, "2026-07-16", "2026-12-31", 100),
+		}, 14, []want{{"2026-07-01", 15, 1, 2}}},
+		{"unsorted", []Assignment{
+			assignment(2, "2026-09-01", "2026-12-31", 100),
+			assignment(1, "2026-01-01", "2026-06-30", 100),
+		}, 14, []want{{"2026-07-01", 62, 1, 2}}},
+		{"covered by longer assignment", []Assignment{
+			assignment(1, "2026-01-01", "2026-12-31", 50),
+			assignment(2, "2026-02-01", "2026-02-28", 50),
+			assignment(3, "2026-06-01", "2026-06-30", 50),
+		}, 14, nil},
+		{"open end", []Assignment{
+			assignment(1, "2026-01-01", "", 100),
+			assignment(2, "2027-01-01", "2027-03-31", 100),
+		}, 14, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []want
+			for _, g := range gaps(tt.assignments, tt.maxDays) {
+				got = append(got, want{FormatDate(g.from), g.days, g.before.ID, g.after.ID})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("gaps = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAllocations(t *testing.T) {
+	engineers := []Person{{
+		Name: "Anna Shevchenko",
+		Assignments: []Assignment{
+			assignment(1, "2026-01-01", "2026-06-30", 60),
+			assignment(2, "2026-03-01", "2026-04-30", 60),
+			assignment(3, "2026-09-01", "2026-08-01", 100),
+			{ID: 4, Status: "Active", RawStart: "Jan 5, 2026"},
+			{ID: 5, Start: date(2026, 10, 1), Status: "Maybe"},
+			{ID: 6, Start: date(2026, 1, 1), Finish: date(2026, 6, 30), Status: "Active", RawInvolvement: "TBD"},
+		},
+	}}
+	var got []Finding
+	for _, f := range CheckAllocations(engineers, AllocationRules{MaxGap: 14}) {
+		got = append(got, Finding{Kind: f.Kind, Assignments: f.Assignments})
+	}
+	want := []Finding{
+		{Kind: FindingDates, Assignments: []int{3}},
+		{Kind: FindingDates, Assignments: []int{4}},
+		{Kind: FindingStatus, Assignments: []int{5}},
+		{Kind: FindingInvolvement, Assignments: []int{6}},
+		{Kind: FindingOverlap, Assignments: []int{1, 2, 6}},
+		{Kind: FindingGap, Assignments: []int{1, 5}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %+v, want %+v", got, want)
+	}
+}
+
+func TestActiveOn(t *testing.T) {
+	march := assignment(1, "2026-03-01", "2026-03-31", 100)
+	unknown := Assignment{ID: 2, RawStart: "01/02/2019", RawFinish: "03/04/2019", Involvement: 100}
+	unknownStart := assignment(3, "", "2026-03-31", 100)
+	unknownStart.RawStart = "soon"
+	tests := []struct {
+		name string
+		a    Assignment
+		date time.Time
+		want bool
+	}{
+		{"day before", march, date(2026, 2, 28), false},
+		{"first day", march, date(2026, 3, 1), true},
+		{"end of last day", march, time.Date(2026, 3, 31, 23, 59, 0, 0, time.UTC), true},
+		{"day after", march, date(2026, 4, 1), false},
+		{"unknown dates", unknown, date(2026, 3, 15), false},
+		{"unknown start", unknownStart, date(2026, 3, 15), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.ActiveOn(tt.date); got != tt.want {
+				t.Errorf("ActiveOn(%v) = %v, want %v", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignmentOverlaps(t *testing.T) {
+	unknown := Assignment{ID: 3, RawStart: "01/02/2019", RawFinish: "03/04/2019", Involvement: 100}
+	tests := []struct {
+		name string
+		a, b Assignment
+		want bool
+	}{
+		{"same dates", assignment(1, "2026-01-01", "2026-03-31", 50), assignment(2, "2026-01-01", "2026-03-31", 50), true},
+		{"one common day", assignment(1, "2026-01-01", "2026-03-31", 50), assignment(2, "2026-03-31", "", 50), true},
+		{"sequential", assignment(1, "2026-01-01", "2026-03-31", 50), assignment(2, "2026-04-01", "", 50), false},
+		{"both open", assignment(1, "", "", 50), assignment(2, "", "", 50), true},
+		{"unknown dates", assignment(1, "", "", 50), unknown, false},
+		{"unknown dates first", unknown, assignment(1, "", "", 50), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Overlaps(tt.b); got != tt.want {
+				t.Errorf("Overlaps = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
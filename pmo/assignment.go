@@ -0,0 +1,253 @@
+package pmo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout is layout of dates in output
+const DateLayout = "2006-01-02"
+
+// dateLayouts are date formats sent by PMO and understood by template date functions
+var dateLayouts = []string{DateLayout, time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "02.01.2006"}
+
+// Assignment defines engineer assignment to a project
+type Assignment struct {
+	ID         int
+	EmployeeID int
+	Account    string
+	Project    string
+	// Start and Finish are the first and the last days of assignment. Zero value means open end.
+	Start  time.Time
+	Finish time.Time
+	// Involvement is part of working time in percent, 100 is full time
+	Involvement float64
+	Status      string
+	Comment     string
+	// RawStart and RawFinish keep dates sent by PMO in unknown format. Start and Finish are zero then
+	// and assignment is treated as inactive.
+	RawStart  string
+	RawFinish string
+	// RawInvolvement keeps involvement sent by PMO in unknown format. Involvement is zero then.
+	RawInvolvement string
+}
+
+// assignmentJSON is assignment as sent by PMO
+type assignmentJSON struct {
+	ID          int             `json:"id"`
+	EmployeeID  int             `json:"employeeId"`
+	Account     string          `json:"account"`
+	Project     string          `json:"project"`
+	Start       json.RawMessage `json:"start"`
+	Finish      json.RawMessage `json:"finish"`
+	StartDate   json.RawMessage `json:"startDate"`
+	FinishDate  json.RawMessage `json:"finishDate"`
+	Involvement json.RawMessage `json:"involvement"`
+	Status      string          `json:"status"`
+	Comment     string          `json:"comment"`
+}
+
+// UnmarshalJSON decodes assignment sent by PMO. Dates are taken from `start` and `finish`
+// or from `startDate` and `finishDate` if former are empty. Date in unknown format does not fail
+// decoding: it is kept in RawStart or RawFinish and the date is left zero. The same goes for involvement
+// kept in RawInvolvement.
+func (a *Assignment) UnmarshalJSON(data []byte) error {
+	var raw assignmentJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var rawStart, rawFinish string
+	start, err := parseDateJSON(raw.Start, raw.StartDate)
+	if err != nil {
+		rawStart = rawValue(raw.Start, raw.StartDate)
+	}
+	finish, err := parseDateJSON(raw.Finish, raw.FinishDate)
+	if err != nil {
+		rawFinish = rawValue(raw.Finish, raw.FinishDate)
+	}
+	var rawInvolvement string
+	involvement, err := parsePercent(raw.Involvement)
+	if err != nil {
+		involvement, rawInvolvement = 0, rawValue(raw.Involvement)
+	}
+	*a = Assignment{
+		ID:             raw.ID,
+		EmployeeID:     raw.EmployeeID,
+		Account:        raw.Account,
+		Project:        raw.Project,
+		Start:          start,
+		Finish:         finish,
+		Involvement:    involvement,
+		Status:         raw.Status,
+		Comment:        raw.Comment,
+		RawStart:       rawStart,
+		RawFinish:      rawFinish,
+		RawInvolvement: rawInvolvement,
+	}
+	return nil
+}
+
+// HasInvalidDates reports whether PMO sent start or finish of assignment in unknown format
+func (a Assignment) HasInvalidDates() bool {
+	return a.RawStart != "" || a.RawFinish != ""
+}
+
+// HasInvalidInvolvement reports whether PMO sent involvement of assignment in unknown format
+func (a Assignment) HasInvalidInvolvement() bool {
+	return a.RawInvolvement != ""
+}
+
+// MarshalJSON encodes assignment with dates in DateLayout. Dates and involvement in unknown format
+// are written as received.
+func (a Assignment) MarshalJSON() ([]byte, error) {
+	start, finish := FormatDate(a.Start), FormatDate(a.Finish)
+	if a.RawStart != "" {
+		start = a.RawStart
+	}
+	if a.RawFinish != "" {
+		finish = a.RawFinish
+	}
+	var involvement interface{} = a.Involvement
+	if a.RawInvolvement != "" {
+		involvement = a.RawInvolvement
+	}
+	return json.Marshal(struct {
+		ID          int         `json:"id"`
+		EmployeeID  int         `json:"employeeId"`
+		Account     string      `json:"account"`
+		Project     string      `json:"project"`
+		Start       string      `json:"start"`
+		Finish      string      `json:"finish"`
+		Involvement interface{} `json:"involvement"`
+		Status      string      `json:"status"`
+		Comment     string      `json:"comment"`
+	}{a.ID, a.EmployeeID, a.Account, a.Project, start, finish, involvement, a.Status, a.Comment})
+}
+
+// ActiveOn reports whether assignment covers the day of date.
+// Assignment with dates in unknown format is never active.
+func (a Assignment) ActiveOn(date time.Time) bool {
+	if a.HasInvalidDates() {
+		return false
+	}
+	day := Day(date)
+	return (a.Start.IsZero() || !day.Before(a.Start)) && (a.Finish.IsZero() || !day.After(a.Finish))
+}
+
+// EndsWithin reports whether assignment finishes in d after the day of date, date included
+func (a Assignment) EndsWithin(date time.Time, d time.Duration) bool {
+	if a.Finish.IsZero() || a.HasInvalidDates() {
+		return false
+	}
+	day := Day(date)
+	return !a.Finish.Before(day) && !a.Finish.After(day.Add(d))
+}
+
+// Overlaps reports whether assignments have at least one day in common.
+// Assignment with dates in unknown format overlaps nothing.
+func (a Assignment) Overlaps(other Assignment) bool {
+	if a.HasInvalidDates() || other.HasInvalidDates() {
+		return false
+	}
+	startsBeforeEnd := a.Start.IsZero() || other.Finish.IsZero() || !a.Start.After(other.Finish)
+	endsAfterStart := a.Finish.IsZero() || other.Start.IsZero() || !a.Finish.Before(other.Start)
+	return startsBeforeEnd && endsAfterStart
+}
+
+// String returns assignment in form `"account"-"project"-involvement`
+func (a Assignment) String() string {
+	return fmt.Sprintf("%q-%q-%g", a.Account, a.Project, a.Involvement)
+}
+
+// Day returns midnight UTC of the calendar day of t
+func Day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// FormatDate formats date in DateLayout. Zero date is empty string.
+func FormatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(DateLayout)
+}
+
+// ParseDate parses date in one of formats sent by PMO. Time of day is dropped.
+func ParseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return Day(t), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date format %q", value)
+}
+
+// parseDateJSON parses the first non-empty of JSON values: date string or unix time in milliseconds
+func parseDateJSON(values ...json.RawMessage) (time.Time, error) {
+	for _, value := range values {
+		var v interface{}
+		if len(value) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(value, &v); err != nil {
+			return time.Time{}, err
+		}
+		switch v := v.(type) {
+		case nil:
+		case string:
+			if v = strings.TrimSpace(v); v != "" {
+				return ParseDate(v)
+			}
+		case float64:
+			return Day(time.Unix(0, int64(v)*int64(time.Millisecond)).UTC()), nil
+		default:
+			return time.Time{}, fmt.Errorf("unexpected date %s", value)
+		}
+	}
+	return time.Time{}, nil
+}
+
+// rawValue returns the first non-empty of JSON values as text: strings unquoted, other values as is
+func rawValue(values ...json.RawMessage) string {
+	for _, value := range values {
+		var s string
+		switch {
+		case len(value) == 0 || string(value) == "null":
+		case json.Unmarshal(value, &s) == nil:
+			if s = strings.TrimSpace(s); s != "" {
+				return s
+			}
+		default:
+			return string(value)
+		}
+	}
+	return ""
+}
+
+// parsePercent parses involvement sent as number or string like "50" or "50%"
+func parsePercent(value json.RawMessage) (float64, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(value, &v); err != nil {
+		return 0, err
+	}
+	switch v := v.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return v, nil
+	case string:
+		v = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(v), "%"))
+		if v == "" {
+			return 0, nil
+		}
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("unexpected involvement %s", value)
+	}
+}
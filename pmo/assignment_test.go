@@ -0,0 +1,153 @@
+package pmo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// date returns midnight UTC of the day
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseDateJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    time.Time
+		wantErr bool
+	}{
+		{"date", []string{`"2024-01-05"`}, date(2024, 1, 5), false},
+		{"RFC3339", []string{`"2024-01-05T23:30:00+02:00"`}, date(2024, 1, 5), false},
+		{"date and time", []string{`"2024-01-05T10:00:00"`}, date(2024, 1, 5), false},
+		{"date and time with space", []string{`"2024-01-05 10:00:00"`}, date(2024, 1, 5), false},
+		{"dotted", []string{`"05.01.2024"`}, date(2024, 1, 5), false},
+		{"spaces", []string{`" 2024-01-05 "`}, date(2024, 1, 5), false},
+		{"epoch milliseconds", []string{`1704412800000`}, date(2024, 1, 5), false},
+		{"absent", nil, time.Time{}, false},
+		{"null", []string{`null`}, time.Time{}, false},
+		{"empty string", []string{`""`}, time.Time{}, false},
+		{"first empty", []string{`""`, `"2024-02-15"`}, date(2024, 2, 15), false},
+		{"first null", []string{`null`, `"2024-02-15"`}, date(2024, 2, 15), false},
+		{"first wins", []string{`"2024-01-05"`, `"2024-02-15"`}, date(2024, 1, 5), false},
+		{"unknown format", []string{`"Jan 5, 2024"`}, time.Time{}, true},
+		{"slashes", []string{`"15/02/2024"`}, time.Time{}, true},
+		{"boolean", []string{`true`}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var values []json.RawMessage
+			for _, v := range tt.values {
+				values = append(values, json.RawMessage(v))
+			}
+			got, err := parseDateJSON(values...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDateJSON(%v) error = %v, want error %v", tt.values, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDateJSON(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{``, 0, false},
+		{`null`, 0, false},
+		{`50`, 50, false},
+		{`12.5`, 12.5, false},
+		{`"50"`, 50, false},
+		{`" 50 % "`, 50, false},
+		{`""`, 0, false},
+		{`"half"`, 0, true},
+		{`[50]`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parsePercent(json.RawMessage(tt.value))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePercent(%s) error = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parsePercent(%s) = %g, want %g", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignmentUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name                string
+		json                string
+		start, finish       time.Time
+		rawStart, rawFinish string
+		involvement         float64
+		rawInvolvement      string
+	}{
+		{"start and finish", `{"start":"2024-01-05","finish":"2024-02-15","involvement":50}`,
+			date(2024, 1, 5), date(2024, 2, 15), "", "", 50, ""},
+		{"startDate and finishDate", `{"startDate":"2024-01-05","finishDate":1708000000000}`,
+			date(2024, 1, 5), date(2024, 2, 15), "", "", 0, ""},
+		{"open end", `{"start":"2024-01-05","finish":null}`, date(2024, 1, 5), time.Time{}, "", "", 0, ""},
+		{"unknown formats", `{"start":"Jan 5, 2024","finish":"15/02/2024"}`,
+			time.Time{}, time.Time{}, "Jan 5, 2024", "15/02/2024", 0, ""},
+		{"unknown start only", `{"start":"soon","finish":"2024-02-15"}`, time.Time{}, date(2024, 2, 15), "soon", "", 0, ""},
+		{"unknown finish in finishDate", `{"start":"2024-01-05","finish":"","finishDate":"Feb 15"}`,
+			date(2024, 1, 5), time.Time{}, "", "Feb 15", 0, ""},
+		{"unknown involvement", `{"start":"2024-01-05","involvement":"TBD"}`,
+			date(2024, 1, 5), time.Time{}, "", "", 0, "TBD"},
+		{"involvement out of range", `{"involvement":"1e999"}`, time.Time{}, time.Time{}, "", "", 0, "1e999"},
+		{"involvement array", `{"involvement":[50]}`, time.Time{}, time.Time{}, "", "", 0, "[50]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a Assignment
+			if err := json.Unmarshal([]byte(tt.json), &a); err != nil {
+				t.Fatalf("Unmarshal(%s) returned error: %v", tt.json, err)
+			}
+			if !a.Start.Equal(tt.start) || !a.Finish.Equal(tt.finish) {
+				t.Errorf("dates are %v..%v, want %v..%v", a.Start, a.Finish, tt.start, tt.finish)
+			}
+			if a.RawStart != tt.rawStart || a.RawFinish != tt.rawFinish {
+				t.Errorf("raw dates are %q..%q, want %q..%q", a.RawStart, a.RawFinish, tt.rawStart, tt.rawFinish)
+			}
+			if a.HasInvalidDates() != (tt.rawStart != "" || tt.rawFinish != "") {
+				t.Errorf("HasInvalidDates() = %v", a.HasInvalidDates())
+			}
+			if a.Involvement != tt.involvement || a.RawInvolvement != tt.rawInvolvement {
+				t.Errorf("involvement is %g, raw %q, want %g, raw %q",
+					a.Involvement, a.RawInvolvement, tt.involvement, tt.rawInvolvement)
+			}
+		})
+	}
+}
+
+func TestAssignmentJSONRoundTrip(t *testing.T) {
+	raw := `{"data":[{"id":1,"assignments":[{"id":5,"start":"Jan 5, 2024","finish":"2024-02-15","involvement":"50%"},
+		{"id":6,"start":"2024-01-05","involvement":"TBD"}]}]}`
+	var response APIResponse
+	if err := json.Unmarshal([]byte(raw), &response); err != nil {
+		t.Fatalf("people list with unknown date and involvement formats is not decoded: %v", err)
+	}
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded APIResponse
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	a := decoded.Data[0].Assignments[0]
+	if a.RawStart != "Jan 5, 2024" || !a.Finish.Equal(date(2024, 2, 15)) || a.Involvement != 50 {
+		t.Errorf("assignment after round trip is %+v", a)
+	}
+	if a := decoded.Data[0].Assignments[1]; a.RawInvolvement != "TBD" || a.Involvement != 0 {
+		t.Errorf("assignment with unknown involvement after round trip is %+v", a)
+	}
+}
@@ -0,0 +1,169 @@
+package pmo
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// BenchEntry is engineer with total involvement below threshold
+type BenchEntry struct {
+	Name          string  `json:"name"`
+	Username      string  `json:"username"`
+	Location      string  `json:"location"`
+	Grade         string  `json:"grade"`
+	Profile       string  `json:"profile"`
+	Involvement   float64 `json:"involvement"`
+	DaysOnBench   int     `json:"daysOnBench"`
+	AvailableDays int     `json:"availableDays"`
+	LastAccount   string  `json:"lastAccount"`
+	// LongBench is set if engineer is on bench longer than threshold
+	LongBench bool   `json:"longBench"`
+	Source    string `json:"source,omitempty"`
+}
+
+// BenchTotal is number of engineers on bench with the same location, grade or profile
+type BenchTotal struct {
+	By                 string  `json:"by"`
+	Value              string  `json:"value"`
+	Engineers          int     `json:"engineers"`
+	LongBench          int     `json:"longBench"`
+	AverageDaysOnBench float64 `json:"averageDaysOnBench"`
+}
+
+// BenchReport lists engineers on bench with totals by location, grade and profile
+type BenchReport struct {
+	Engineers []BenchEntry `json:"engineers"`
+	Totals    []BenchTotal `json:"totals"`
+}
+
+// Bench returns engineers with total involvement on date below threshold in percent.
+// Engineers on bench more than longBench days are flagged. Engineers are sorted by bench duration, longest first.
+func Bench(engineers []Person, date time.Time, below float64, longBench int) BenchReport {
+	report := BenchReport{Engineers: []BenchEntry{}, Totals: []BenchTotal{}}
+	for _, engineer := range engineers {
+		current := engineer.AsOf(date)
+		involvement := current.TotalInvolvement()
+		if involvement >= below {
+			continue
+		}
+		report.Engineers = append(report.Engineers, BenchEntry{
+			Name:          engineer.Name,
+			Username:      engineer.Username,
+			Location:      engineer.Location,
+			Grade:         engineer.Grade,
+			Profile:       engineer.Profile,
+			Involvement:   involvement,
+			DaysOnBench:   engineer.DaysOnBench,
+			AvailableDays: engineer.AvailableDays,
+			LastAccount:   engineer.lastAccount(date),
+			LongBench:     engineer.DaysOnBench > longBench,
+			Source:        engineer.Source,
+		})
+	}
+	sort.SliceStable(report.Engineers, func(i, j int) bool {
+		a, b := report.Engineers[i], report.Engineers[j]
+		if a.DaysOnBench != b.DaysOnBench {
+			return a.DaysOnBench > b.DaysOnBench
+		}
+		return a.Name < b.Name
+	})
+
+	for _, by := range []struct {
+		name string
+		key  func(BenchEntry) string
+	}{
+		{"location", func(e BenchEntry) string { return e.Location }},
+		{"grade", func(e BenchEntry) string { return e.Grade }},
+		{"profile", func(e BenchEntry) string { return e.Profile }},
+	} {
+		report.Totals = append(report.Totals, benchTotals(report.Engineers, by.name, by.key)...)
+	}
+	return report
+}
+
+// benchTotals groups bench entries by key
+func benchTotals(entries []BenchEntry, by string, key func(BenchEntry) string) []BenchTotal {
+	totals := map[string]*BenchTotal{}
+	days := map[string]int{}
+	var values []string
+	for _, entry := range entries {
+		value := key(entry)
+		total, ok := totals[value]
+		if !ok {
+			total = &BenchTotal{By: by, Value: value}
+			totals[value] = total
+			values = append(values, value)
+		}
+		total.Engineers++
+		if entry.LongBench {
+			total.LongBench++
+		}
+		days[value] += entry.DaysOnBench
+	}
+	sort.Strings(values)
+
+	result := make([]BenchTotal, 0, len(values))
+	for _, value := range values {
+		total := totals[value]
+		total.AverageDaysOnBench = float64(days[value]) / float64(total.Engineers)
+		result = append(result, *total)
+	}
+	return result
+}
+
+// lastAccount returns account of the latest assignment started on or before date. Assignments with dates
+// in unknown format are skipped.
+func (p *Person) lastAccount(date time.Time) string {
+	var last *Assignment
+	day := Day(date)
+	for i := range p.Assignments {
+		a := &p.Assignments[i]
+		if a.Start.After(day) || a.HasInvalidDates() {
+			continue
+		}
+		if last == nil || finishesLater(*a, *last) {
+			last = a
+		}
+	}
+	if last == nil {
+		return ""
+	}
+	return last.Account
+}
+
+// finishesLater reports whether assignment a finishes after b. Assignment without finish never ends.
+func finishesLater(a, b Assignment) bool {
+	switch {
+	case b.Finish.IsZero():
+		return false
+	case a.Finish.IsZero():
+		return true
+	default:
+		return a.Finish.After(b.Finish)
+	}
+}
+
+// Tables returns bench report as table of engineers and table of totals
+func (r BenchReport) Tables() []Table {
+	engineers := Table{Title: "Engineers", Header: []string{"Name", "Location", "Grade", "Profile", "Involvement",
+		"DaysOnBench", "AvailableDays", "LastAccount", "LongBench"}}
+	sources := make([]string, 0, len(r.Engineers))
+	for _, e := range r.Engineers {
+		long := ""
+		if e.LongBench {
+			long = "yes"
+		}
+		engineers.Rows = append(engineers.Rows, []interface{}{e.Name, e.Location, e.Grade, e.Profile, e.Involvement,
+			e.DaysOnBench, e.AvailableDays, e.LastAccount, long})
+		sources = append(sources, e.Source)
+	}
+	engineers.addSourceColumn(sources)
+
+	totals := Table{Title: "Totals", Header: []string{"By", "Value", "Engineers", "LongBench", "AverageDaysOnBench"}}
+	for _, t := range r.Totals {
+		totals.Rows = append(totals.Rows, []interface{}{t.By, t.Value, t.Engineers, t.LongBench,
+			strconv.FormatFloat(t.AverageDaysOnBench, 'f', 1, 64)})
+	}
+	return []Table{engineers, totals}
+}
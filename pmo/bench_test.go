@@ -0,0 +1,112 @@
+package pmo
+
+import (
+	"reflect"
+	"testing"
+)
+
+// benchEngineers are engineers used by bench tests, bench report is made on 2026-10-18
+var benchEngineers = []Person{
+	{Name: "Ivan", Location: "Kyiv", Grade: "SE3", Profile: "Backend", DaysOnBench: 0,
+		Assignments: []Assignment{assignment(11, "2026-01-01", "2026-12-31", 100)}},
+	{Name: "Oleksii", Location: "Lviv", Grade: "SE2", Profile: "Backend", DaysOnBench: 25, Assignments: []Assignment{
+		{ID: 21, Account: "Globex", Start: date(2026, 1, 1), Finish: date(2026, 9, 23), Involvement: 100},
+		{ID: 22, Account: "Acme", Start: date(2026, 12, 1), Involvement: 100},
+	}},
+	{Name: "Anna", Location: "Kyiv", Grade: "SE2", Profile: "Frontend", DaysOnBench: 40, Assignments: []Assignment{
+		{ID: 31, Account: "Initech", Start: date(2026, 1, 1), Finish: date(2026, 12, 31), Involvement: 50},
+	}},
+	{Name: "Mykola", Location: "Kyiv", Grade: "SE1", Profile: "Frontend", DaysOnBench: 40},
+}
+
+func TestBench(t *testing.T) {
+	type entry struct {
+		name        string
+		involvement float64
+		lastAccount string
+		longBench   bool
+	}
+	tests := []struct {
+		name  string
+		below float64
+		want  []entry
+	}{
+		{"below 100%", 100, []entry{
+			{"Anna", 50, "Initech", true},
+			{"Mykola", 0, "", true},
+			{"Oleksii", 0, "Globex", false},
+		}},
+		{"free only", 1, []entry{
+			{"Mykola", 0, "", true},
+			{"Oleksii", 0, "Globex", false},
+		}},
+		{"nobody", 0, []entry{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Bench(benchEngineers, date(2026, 10, 18), tt.below, 30)
+			got := []entry{}
+			for _, e := range report.Engineers {
+				got = append(got, entry{e.Name, e.Involvement, e.LastAccount, e.LongBench})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Bench engineers = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBenchTotals(t *testing.T) {
+	entries := []BenchEntry{
+		{Name: "Anna", Location: "Kyiv", DaysOnBench: 40, LongBench: true},
+		{Name: "Mykola", Location: "Kyiv", DaysOnBench: 10},
+		{Name: "Oleksii", Location: "Lviv", DaysOnBench: 25},
+	}
+	got := benchTotals(entries, "location", func(e BenchEntry) string { return e.Location })
+	want := []BenchTotal{
+		{By: "location", Value: "Kyiv", Engineers: 2, LongBench: 1, AverageDaysOnBench: 25},
+		{By: "location", Value: "Lviv", Engineers: 1, LongBench: 0, AverageDaysOnBench: 25},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("benchTotals = %+v, want %+v", got, want)
+	}
+	if got := benchTotals(nil, "location", func(e BenchEntry) string { return e.Location }); len(got) != 0 {
+		t.Errorf("benchTotals of nobody = %+v", got)
+	}
+}
+
+func TestLastAccount(t *testing.T) {
+	tests := []struct {
+		name        string
+		assignments []Assignment
+		want        string
+	}{
+		{"none", nil, ""},
+		{"finished", []Assignment{{Account: "Acme", Start: date(2026, 1, 1), Finish: date(2026, 9, 30)}}, "Acme"},
+		{"latest finish wins", []Assignment{
+			{Account: "Globex", Start: date(2026, 1, 1), Finish: date(2026, 9, 30)},
+			{Account: "Acme", Start: date(2025, 1, 1), Finish: date(2026, 10, 10)},
+			{Account: "Initech", Start: date(2026, 3, 1), Finish: date(2026, 6, 30)},
+		}, "Acme"},
+		{"open end wins", []Assignment{
+			{Account: "Globex", Start: date(2026, 1, 1)},
+			{Account: "Acme", Start: date(2026, 2, 1), Finish: date(2026, 12, 31)},
+		}, "Globex"},
+		{"future is skipped", []Assignment{
+			{Account: "Globex", Start: date(2026, 1, 1), Finish: date(2026, 9, 30)},
+			{Account: "Acme", Start: date(2026, 11, 1)},
+		}, "Globex"},
+		{"unknown dates are skipped", []Assignment{
+			{Account: "Globex", Start: date(2026, 1, 1), Finish: date(2026, 9, 30)},
+			{Account: "Acme", RawStart: "01/02/2019"},
+		}, "Globex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Person{Assignments: tt.assignments}
+			if got := p.lastAccount(date(2026, 10, 18)); got != tt.want {
+				t.Errorf("lastAccount = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
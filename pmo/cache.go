@@ -0,0 +1,119 @@
+package pmo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheTTL is how long cached people list is used when config has no cacheTTL
+const DefaultCacheTTL = 15 * time.Minute
+
+// cachedResponse is a content of cache file: people list and time it was fetched at
+type cachedResponse struct {
+	// URL is PeopleListURL the list was fetched from
+	URL      string      `json:"url"`
+	Time     time.Time   `json:"time"`
+	Response APIResponse `json:"response"`
+}
+
+// DefaultCacheFile returns path of the file used to cache people list of config between runs.
+// File name includes profile name and hash of PeopleListURL, so configs of different PMO instances
+// do not share cache even without profiles.
+func DefaultCacheFile(config Configuration) (string, error) {
+	dir, err := sessionDir()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(config.PeopleListURL))
+	name := "people-"
+	if config.Name != "" {
+		name += safeFileName(config.Name) + "-"
+	}
+	return filepath.Join(dir, name+hex.EncodeToString(hash[:6])+".json"), nil
+}
+
+// CacheDuration returns how long cached people list is valid: cacheTTL from config or DefaultCacheTTL.
+// Zero means cache is used only by PeopleCache.Load with anyAge.
+func (config Configuration) CacheDuration() (time.Duration, error) {
+	if config.CacheTTL == "" {
+		return DefaultCacheTTL, nil
+	}
+	ttl, err := ParsePeriod(config.CacheTTL)
+	if err != nil {
+		return 0, fmt.Errorf("%w: cacheTTL: %v", ErrConfig, err)
+	}
+	return ttl, nil
+}
+
+// PeopleCache keeps the latest people list fetched from PeopleListURL in a file between runs
+type PeopleCache struct {
+	path string
+	url  string
+	ttl  time.Duration
+}
+
+// NewPeopleCache returns cache of people list fetched from url in path. Cached list is fresh while
+// it is younger than ttl.
+func NewPeopleCache(path, url string, ttl time.Duration) *PeopleCache {
+	return &PeopleCache{path: path, url: url, ttl: ttl}
+}
+
+// Load returns cached people list and time it was fetched at. List older than TTL is not returned
+// unless anyAge is set. Returns ErrNoCache if there is no suitable list.
+func (c *PeopleCache) Load(anyAge bool) (APIResponse, time.Time, error) {
+	raw, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return APIResponse{}, time.Time{}, fmt.Errorf("%w: %v not found", ErrNoCache, c.path)
+	}
+	if err != nil {
+		return APIResponse{}, time.Time{}, fmt.Errorf("%w: can not read cache file %v: %v", ErrNoCache, c.path, err)
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(raw, &cached); err != nil {
+		return APIResponse{}, time.Time{}, fmt.Errorf("%w: can not decode cache file %v: %v", ErrNoCache, c.path, err)
+	}
+	if cached.URL != c.url {
+		return APIResponse{}, time.Time{}, fmt.Errorf("%w: cache file %v is fetched from %q, not from %q",
+			ErrNoCache, c.path, cached.URL, c.url)
+	}
+	if age := time.Since(cached.Time); !anyAge && age >= c.ttl {
+		return APIResponse{}, time.Time{}, fmt.Errorf("%w: cached people list is %s old", ErrNoCache, FormatAge(age))
+	}
+	return cached.Response, cached.Time, nil
+}
+
+// Save writes people list fetched at t to the cache file
+func (c *PeopleCache) Save(response APIResponse, t time.Time) error {
+	raw, err := json.Marshal(cachedResponse{URL: c.url, Time: t, Response: response})
+	if err != nil {
+		return fmt.Errorf("can not encode cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return fmt.Errorf("can not create cache directory: %w", err)
+	}
+	if err := ioutil.WriteFile(c.path, raw, 0600); err != nil {
+		return fmt.Errorf("can not write cache file %v: %w", c.path, err)
+	}
+	return nil
+}
+
+// FormatAge formats duration for humans: seconds below minute, minutes below two hours,
+// hours below two days and days otherwise
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < 2*time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 2*oneDay:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d days", int(d/oneDay))
+	}
+}
@@ -0,0 +1,108 @@
+package pmo
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPeopleCache(t *testing.T) {
+	dir, remove := configDir(t, map[string]string{"broken.json": "{"})
+	defer remove()
+	const peopleURL = "https://pmo/people"
+	response := APIResponse{Data: []Person{{ID: 1, Name: "Ivan Petrenko"}}}
+
+	fresh := NewPeopleCache(filepath.Join(dir, "fresh.json"), peopleURL, time.Hour)
+	if err := fresh.Save(response, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	stale := NewPeopleCache(filepath.Join(dir, "stale.json"), peopleURL, time.Hour)
+	if err := stale.Save(response, time.Now().Add(-3*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cache   *PeopleCache
+		anyAge  bool
+		wantErr string
+	}{
+		{"fresh", fresh, false, ""},
+		{"stale", stale, false, "cached people list is 3h old"},
+		{"stale with any age", stale, true, ""},
+		{"zero TTL", NewPeopleCache(filepath.Join(dir, "fresh.json"), peopleURL, 0), false, "old"},
+		{"other URL", NewPeopleCache(filepath.Join(dir, "fresh.json"), "https://pmo-staging/people", time.Hour),
+			true, `is fetched from "https://pmo/people"`},
+		{"missing", NewPeopleCache(filepath.Join(dir, "missing.json"), peopleURL, time.Hour), true, "not found"},
+		{"broken", NewPeopleCache(filepath.Join(dir, "broken.json"), peopleURL, time.Hour), true, "can not decode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fetched, err := tt.cache.Load(tt.anyAge)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrNoCache) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Load returned %v, want ErrNoCache with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load returned %v", err)
+			}
+			if !reflect.DeepEqual(got, response) || fetched.IsZero() {
+				t.Errorf("Load = %+v, %v, want %+v", got, fetched, response)
+			}
+		})
+	}
+}
+
+func TestDefaultCacheFile(t *testing.T) {
+	defer setEnv(t, map[string]string{"XDG_CACHE_HOME": "/cache"})()
+	tests := []struct {
+		name   string
+		config Configuration
+		want   string
+	}{
+		{"without profile", Configuration{PeopleListURL: "https://pmo/people"}, "people-"},
+		{"profile", Configuration{Name: "prod", PeopleListURL: "https://pmo/people"}, "people-prod-"},
+		{"unsafe profile", Configuration{Name: "../team a", PeopleListURL: "https://pmo/people"}, "people-..-team-a-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DefaultCacheFile(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filepath.Dir(got) != filepath.Join("/cache", "pmoclient") || !strings.HasPrefix(filepath.Base(got), tt.want) {
+				t.Errorf("DefaultCacheFile = %q, want %q prefix in /cache/pmoclient", got, tt.want)
+			}
+		})
+	}
+
+	other, _ := DefaultCacheFile(Configuration{PeopleListURL: "https://pmo-staging/people"})
+	same, _ := DefaultCacheFile(Configuration{PeopleListURL: "https://pmo/people"})
+	if other == same {
+		t.Errorf("people lists of different URLs share cache file %q", same)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "30s"},
+		{90 * time.Minute, "90m"},
+		{30 * time.Hour, "30h"},
+		{50 * time.Hour, "2 days"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatAge(tt.d); got != tt.want {
+				t.Errorf("FormatAge(%v) = %q, want %q", tt.d, got, tt.want)
+			}
+		})
+	}
+}
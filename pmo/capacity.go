@@ -0,0 +1,212 @@
+package pmo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MonthLayout is layout of months in capacity report
+const MonthLayout = "2006-01"
+
+// DefaultCapacityStatuses are statuses of assignments counted into capacity by default
+var DefaultCapacityStatuses = []string{"Active"}
+
+// CapacityRow is capacity of account project for the period
+type CapacityRow struct {
+	// Period is month in MonthLayout for monthly report or date in DateLayout
+	Period  string  `json:"period"`
+	Account string  `json:"account"`
+	Project string  `json:"project"`
+	FTE     float64 `json:"fte"`
+	Heads   int     `json:"heads"`
+	// Grades and Locations count engineers assigned to the project
+	Grades    map[string]int `json:"grades"`
+	Locations map[string]int `json:"locations"`
+	Source    string         `json:"source,omitempty"`
+}
+
+// Capacity sums involvement of assignments active on date into FTE per account and project.
+// Only assignments with statuses are counted, empty statuses mean DefaultCapacityStatuses.
+func Capacity(engineers []Person, date time.Time, statuses []string) []CapacityRow {
+	day := Day(date)
+	return capacity(engineers, day, day, FormatDate(day), statusSet(statuses))
+}
+
+// MonthlyCapacity returns capacity per account and project for every month from `from` to `to` inclusive.
+// Involvement is weighted by number of days assignment is active in the month. Statuses are used as in Capacity.
+func MonthlyCapacity(engineers []Person, from time.Time, to time.Time, statuses []string) []CapacityRow {
+	var rows []CapacityRow
+	counted := statusSet(statuses)
+	for month := monthStart(from); !month.After(monthStart(to)); month = month.AddDate(0, 1, 0) {
+		rows = append(rows, capacity(engineers, month, month.AddDate(0, 1, -1), month.Format(MonthLayout), counted)...)
+	}
+	return rows
+}
+
+// statusSet returns lower-cased statuses as set. Empty statuses mean DefaultCapacityStatuses.
+func statusSet(statuses []string) map[string]bool {
+	if len(statuses) == 0 {
+		statuses = DefaultCapacityStatuses
+	}
+	set := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		set[strings.ToLower(strings.TrimSpace(status))] = true
+	}
+	return set
+}
+
+// capacity returns capacity per source, account and project for days from start to end inclusive
+// counting assignments with statuses only
+func capacity(engineers []Person, start time.Time, end time.Time, period string,
+	statuses map[string]bool) []CapacityRow {
+	type key struct{ source, account, project string }
+	rows := map[key]*CapacityRow{}
+	heads := map[key]map[int]bool{}
+	days := float64(end.Sub(start)/oneDay + 1)
+
+	for i := range engineers {
+		engineer := &engineers[i]
+		for _, a := range engineer.Assignments {
+			if !statuses[strings.ToLower(a.Status)] {
+				continue
+			}
+			active := activeDays(a, start, end)
+			if active == 0 {
+				continue
+			}
+			k := key{engineer.Source, a.Account, a.Project}
+			row, ok := rows[k]
+			if !ok {
+				row = &CapacityRow{Period: period, Account: a.Account, Project: a.Project, Source: engineer.Source,
+					Grades: map[string]int{}, Locations: map[string]int{}}
+				rows[k] = row
+				heads[k] = map[int]bool{}
+			}
+			row.FTE += a.Involvement / 100 * float64(active) / days
+			if !heads[k][i] {
+				heads[k][i] = true
+				row.Heads++
+				row.Grades[engineer.Grade]++
+				row.Locations[engineer.Location]++
+			}
+		}
+	}
+
+	result := make([]CapacityRow, 0, len(rows))
+	for _, row := range rows {
+		row.FTE = math.Round(row.FTE*100) / 100
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Source != result[j].Source {
+			return result[i].Source < result[j].Source
+		}
+		if result[i].Account != result[j].Account {
+			return result[i].Account < result[j].Account
+		}
+		return result[i].Project < result[j].Project
+	})
+	return result
+}
+
+// activeDays returns number of days assignment is active from start to end inclusive.
+// Assignment with dates in unknown format has none.
+func activeDays(a Assignment, start time.Time, end time.Time) int {
+	if a.HasInvalidDates() {
+		return 0
+	}
+	if !a.Start.IsZero() && a.Start.After(start) {
+		start = a.Start
+	}
+	if !a.Finish.IsZero() && a.Finish.Before(end) {
+		end = a.Finish
+	}
+	if end.Before(start) {
+		return 0
+	}
+	return int(end.Sub(start)/oneDay) + 1
+}
+
+// monthStart returns the first day of month of t
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// CapacityTable returns capacity as table with heads per grade and location
+func CapacityTable(rows []CapacityRow) Table {
+	table := Table{Header: []string{"Period", "Account", "Project", "FTE", "Heads", "Grades", "Locations"}}
+	sources := make([]string, 0, len(rows))
+	for _, row := range rows {
+		table.Rows = append(table.Rows, []interface{}{row.Period, row.Account, row.Project, row.FTE, row.Heads,
+			formatCounts(row.Grades), formatCounts(row.Locations)})
+		sources = append(sources, row.Source)
+	}
+	table.addSourceColumn(sources)
+	return table
+}
+
+// CapacityPivot returns monthly capacity as pivot table: FTE of account project per month with totals.
+// Every month from `from` to `to` inclusive has a column, even if nobody is assigned then.
+func CapacityPivot(rows []CapacityRow, from time.Time, to time.Time) Table {
+	type key struct{ source, account, project string }
+	var periods []string
+	for month := monthStart(from); !month.After(monthStart(to)); month = month.AddDate(0, 1, 0) {
+		periods = append(periods, month.Format(MonthLayout))
+	}
+	var keys []key
+	fte := map[key]map[string]float64{}
+	totals := map[string]float64{}
+	for _, row := range rows {
+		k := key{row.Source, row.Account, row.Project}
+		if _, ok := fte[k]; !ok {
+			fte[k] = map[string]float64{}
+			keys = append(keys, k)
+		}
+		fte[k][row.Period] += row.FTE
+		totals[row.Period] += row.FTE
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].source != keys[j].source {
+			return keys[i].source < keys[j].source
+		}
+		if keys[i].account != keys[j].account {
+			return keys[i].account < keys[j].account
+		}
+		return keys[i].project < keys[j].project
+	})
+
+	table := Table{Header: append([]string{"Account", "Project"}, periods...)}
+	sources := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		row := []interface{}{k.account, k.project}
+		for _, period := range periods {
+			row = append(row, fte[k][period])
+		}
+		table.Rows = append(table.Rows, row)
+		sources = append(sources, k.source)
+	}
+	total := []interface{}{"Total", ""}
+	for _, period := range periods {
+		total = append(total, math.Round(totals[period]*100)/100)
+	}
+	table.Rows = append(table.Rows, total)
+	table.addSourceColumn(append(sources, ""))
+	return table
+}
+
+// formatCounts formats counts as `key:count` sorted by key
+func formatCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s:%d", key, counts[key]))
+	}
+	return strings.Join(parts, ", ")
+}
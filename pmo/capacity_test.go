@@ -0,0 +1,156 @@
+package pmo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestActiveDays(t *testing.T) {
+	march, endOfMarch := date(2026, 3, 1), date(2026, 3, 31)
+	tests := []struct {
+		name string
+		a    Assignment
+		want int
+	}{
+		{"whole month", assignment(1, "2026-01-01", "2026-12-31", 100), 31},
+		{"open end", assignment(1, "2026-01-01", "", 100), 31},
+		{"open start", assignment(1, "", "2026-03-10", 100), 10},
+		{"starts in month", assignment(1, "2026-03-21", "2026-12-31", 100), 11},
+		{"ends in month", assignment(1, "2026-01-01", "2026-03-15", 100), 15},
+		{"inside month", assignment(1, "2026-03-10", "2026-03-19", 100), 10},
+		{"one day", assignment(1, "2026-03-31", "2026-04-30", 100), 1},
+		{"before month", assignment(1, "2026-01-01", "2026-02-28", 100), 0},
+		{"after month", assignment(1, "2026-04-01", "2026-04-30", 100), 0},
+		{"unknown dates", Assignment{ID: 1, RawStart: "01/02/2019", RawFinish: "03/04/2019", Involvement: 100}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := activeDays(tt.a, march, endOfMarch); got != tt.want {
+				t.Errorf("activeDays = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// capacityEngineers are engineers used by capacity tests
+var capacityEngineers = []Person{
+	{Name: "Ivan", Grade: "SE3", Location: "Kyiv", Assignments: []Assignment{
+		{Account: "Acme", Project: "Rocket", Start: date(2026, 1, 1), Finish: date(2026, 4, 15), Involvement: 100,
+			Status: "Active"},
+		{Account: "Initech", Project: "Pilot", Start: date(2026, 3, 1), Involvement: 50, Status: "Proposed"},
+	}},
+	{Name: "Anna", Grade: "SE2", Location: "Lviv", Assignments: []Assignment{
+		{Account: "Acme", Project: "Rocket", Start: date(2026, 3, 1), Involvement: 50, Status: "active"},
+		{Account: "Globex", Project: "Core", Start: date(2026, 3, 1), Finish: date(2026, 3, 31), Involvement: 50,
+			Status: "Active"},
+	}},
+	{Name: "Oleksii", Grade: "SE2", Location: "Kyiv", Assignments: []Assignment{
+		{Account: "Legacy", Project: "Old", RawStart: "01/02/2019", RawFinish: "03/04/2019", Involvement: 100,
+			Status: "Active"},
+		{Account: "Initech", Project: "Pilot", Start: date(2026, 1, 1), Finish: date(2026, 3, 31), Involvement: 100,
+			Status: "Completed"},
+		{Account: "Acme", Project: "Rocket", Start: date(2026, 4, 1), Finish: date(2026, 4, 30), Involvement: 50,
+			Status: "Active"},
+		{Account: "Acme", Project: "Rocket", Start: date(2026, 5, 1), Finish: date(2026, 5, 31), Involvement: 100,
+			Status: "Active"},
+	}},
+}
+
+func TestCapacity(t *testing.T) {
+	got := Capacity(capacityEngineers, date(2026, 3, 15), nil)
+	want := []CapacityRow{
+		{Period: "2026-03-15", Account: "Acme", Project: "Rocket", FTE: 1.5, Heads: 2,
+			Grades: map[string]int{"SE2": 1, "SE3": 1}, Locations: map[string]int{"Kyiv": 1, "Lviv": 1}},
+		{Period: "2026-03-15", Account: "Globex", Project: "Core", FTE: 0.5, Heads: 1,
+			Grades: map[string]int{"SE2": 1}, Locations: map[string]int{"Lviv": 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Capacity = %+v, want %+v", got, want)
+	}
+}
+
+func TestCapacityStatuses(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		want     map[string]float64
+	}{
+		{nil, map[string]float64{"Acme": 1.5, "Globex": 0.5}},
+		{[]string{"Active", " Proposed"}, map[string]float64{"Acme": 1.5, "Globex": 0.5, "Initech": 0.5}},
+		{[]string{"completed"}, map[string]float64{"Initech": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.statuses, ","), func(t *testing.T) {
+			got := map[string]float64{}
+			for _, row := range Capacity(capacityEngineers, date(2026, 3, 15), tt.statuses) {
+				got[row.Account] += row.FTE
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FTE by account = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonthlyCapacity(t *testing.T) {
+	type row struct {
+		period, account string
+		fte             float64
+		heads           int
+	}
+	var got []row
+	for _, r := range MonthlyCapacity(capacityEngineers, date(2026, 3, 20), date(2026, 5, 2), nil) {
+		got = append(got, row{r.Period, r.Account, r.FTE, r.Heads})
+	}
+	want := []row{
+		// Ivan 1 + Anna 0.5
+		{"2026-03", "Acme", 1.5, 2},
+		{"2026-03", "Globex", 0.5, 1},
+		// Ivan 15 of 30 days + Anna 0.5 + Oleksii 0.5; Oleksii has two assignments but is one head
+		{"2026-04", "Acme", 1.5, 3},
+		// Anna 0.5 + Oleksii 1
+		{"2026-05", "Acme", 1.5, 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MonthlyCapacity = %+v, want %+v", got, want)
+	}
+}
+
+func TestCapacityPivot(t *testing.T) {
+	from, to := date(2026, 2, 1), date(2026, 6, 30)
+	table := CapacityPivot(MonthlyCapacity(capacityEngineers, from, to, nil), from, to)
+	// months without assignments have columns too
+	want := Table{
+		Header: []string{"Account", "Project", "2026-02", "2026-03", "2026-04", "2026-05", "2026-06"},
+		Rows: [][]interface{}{
+			{"Acme", "Rocket", 1.0, 1.5, 1.5, 1.5, 0.5},
+			{"Globex", "Core", 0.0, 0.5, 0.0, 0.0, 0.0},
+			{"Total", "", 1.0, 2.0, 1.5, 1.5, 0.5},
+		},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("CapacityPivot = %+v, want %+v", table, want)
+	}
+}
+
+func TestCapacityPivotSources(t *testing.T) {
+	engineers := []Person{
+		{Name: "Ivan", Source: "prod", Assignments: []Assignment{
+			{Account: "Acme", Project: "Rocket", Involvement: 100, Status: "Active"}}},
+		{Name: "Anna", Source: "staging", Assignments: []Assignment{
+			{Account: "Acme", Project: "Rocket", Involvement: 50, Status: "Active"}}},
+	}
+	month := date(2026, 3, 1)
+	table := CapacityPivot(MonthlyCapacity(engineers, month, month, nil), month, month)
+	want := Table{
+		Header: []string{"Source", "Account", "Project", "2026-03"},
+		Rows: [][]interface{}{
+			{"prod", "Acme", "Rocket", 1.0},
+			{"staging", "Acme", "Rocket", 0.5},
+			{"", "Total", "", 1.5},
+		},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("CapacityPivot = %+v, want %+v", table, want)
+	}
+}
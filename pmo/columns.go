@@ -0,0 +1,195 @@
+package pmo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Column is a field of Person or a value derived from it
+type Column struct {
+	Name   string
+	Header string
+	// Value returns string, int or bool. Lists are joined with comma.
+	Value func(p *Person) interface{}
+}
+
+// Columns lists all available columns
+var Columns = []Column{
+	{"id", "ID", func(p *Person) interface{} { return p.ID }},
+	{"name", "Name", func(p *Person) interface{} { return p.Name }},
+	{"username", "Username", func(p *Person) interface{} { return p.Username }},
+	{"grade", "Grade", func(p *Person) interface{} { return p.Grade }},
+	{"specialization", "Specialization", func(p *Person) interface{} { return p.Specialization }},
+	{"profile", "Profile", func(p *Person) interface{} { return p.Profile }},
+	{"position", "Position", func(p *Person) interface{} { return p.Position }},
+	{"serviceLine", "ServiceLine", func(p *Person) interface{} { return p.ServiceLine }},
+	{"location", "Location", func(p *Person) interface{} { return p.Location }},
+	{"manager", "Manager", func(p *Person) interface{} { return p.Manager }},
+	{"availableDays", "AvailableDays", func(p *Person) interface{} { return p.AvailableDays }},
+	{"daysOnBench", "DaysOnBench", func(p *Person) interface{} { return p.DaysOnBench }},
+	{"inBusinessTrip", "InBusinessTrip", func(p *Person) interface{} { return p.InBusinessTrip }},
+	{"source", "Source", func(p *Person) interface{} { return p.Source }},
+	// derived values
+	{"accounts", "Account", func(p *Person) interface{} { return p.GetAccountsString() }},
+	{"projects", "Project", func(p *Person) interface{} { return p.GetProjectsString() }},
+	{"statuses", "Status", func(p *Person) interface{} {
+		return strings.Join(RemoveDuplicates(p.AssignmentStatuses()), ",")
+	}},
+	{"involvement", "Involvement", func(p *Person) interface{} { return p.TotalInvolvement() }},
+	{"engineerManagers", "EngineeringManagers", func(p *Person) interface{} {
+		return strings.Join(p.GetEngineerManagers(), ",")
+	}},
+	{"assignments", "Assignments", func(p *Person) interface{} {
+		return strings.Join(p.GetAssignmentsString(), ",")
+	}},
+}
+
+// DefaultColumns are columns of the table output
+var DefaultColumns = []string{"name", "grade", "profile", "accounts", "projects", "manager", "statuses"}
+
+// DefaultSort is the order of engineers if no sort keys are given
+var DefaultSort = []SortKey{{Column: mustColumn("location")}}
+
+// ColumnByName returns column by its name. Name is case-insensitive.
+func ColumnByName(name string) (Column, error) {
+	for _, column := range Columns {
+		if strings.EqualFold(column.Name, strings.TrimSpace(name)) {
+			return column, nil
+		}
+	}
+	return Column{}, fmt.Errorf("%w: unknown column %q, use one of %s", ErrInvalidOption, name, strings.Join(ColumnNames(), ", "))
+}
+
+// ColumnNames returns names of all columns
+func ColumnNames() []string {
+	names := make([]string, 0, len(Columns))
+	for _, column := range Columns {
+		names = append(names, column.Name)
+	}
+	return names
+}
+
+// ParseColumns parses comma-separated list of column names
+func ParseColumns(spec string) ([]Column, error) {
+	return columnsByName(strings.Split(spec, ","))
+}
+
+// columnsByName returns columns with names
+func columnsByName(names []string) ([]Column, error) {
+	columns := make([]Column, 0, len(names))
+	for _, name := range names {
+		column, err := ColumnByName(name)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// mustColumn returns column by name and panics if there is no such column
+func mustColumn(name string) Column {
+	column, err := ColumnByName(name)
+	if err != nil {
+		panic(err)
+	}
+	return column
+}
+
+// SortKey defines sorting by column
+type SortKey struct {
+	Column     Column
+	Descending bool
+}
+
+// ParseSortKeys parses comma-separated list of sort keys. Key is a column name
+// prefixed with '-' or suffixed with ':desc' for descending order, e.g. `location,-daysOnBench`.
+func ParseSortKeys(spec string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		key := SortKey{}
+		switch {
+		case strings.HasPrefix(part, "-"):
+			key.Descending = true
+			part = part[1:]
+		case strings.HasPrefix(part, "+"):
+			part = part[1:]
+		}
+		if i := strings.LastIndex(part, ":"); i >= 0 {
+			switch strings.ToLower(part[i+1:]) {
+			case "desc":
+				key.Descending = true
+			case "asc":
+			default:
+				return nil, fmt.Errorf("%w: unknown sort order %q, use asc or desc", ErrInvalidOption, part[i+1:])
+			}
+			part = part[:i]
+		}
+		column, err := ColumnByName(part)
+		if err != nil {
+			return nil, err
+		}
+		key.Column = column
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// SortEngineers sorts engineers by keys. Order of engineers equal by all keys is preserved.
+func SortEngineers(engineers []Person, keys []SortKey) {
+	sort.SliceStable(engineers, func(i, j int) bool {
+		for _, key := range keys {
+			c := compareValues(key.Column.Value(&engineers[i]), key.Column.Value(&engineers[j]))
+			if c == 0 {
+				continue
+			}
+			if key.Descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// compareValues compares column values: -1 if a < b, 1 if a > b, 0 otherwise.
+// Numbers are compared numerically, strings case-insensitively.
+func compareValues(a, b interface{}) int {
+	if a, ok := toFloat(a); ok {
+		b, _ := toFloat(b)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+	switch a := a.(type) {
+	case bool:
+		b, _ := b.(bool)
+		switch {
+		case !a && b:
+			return -1
+		case a && !b:
+			return 1
+		}
+		return 0
+	default:
+		return strings.Compare(strings.ToLower(fmt.Sprint(a)), strings.ToLower(fmt.Sprint(b)))
+	}
+}
+
+// toFloat converts numeric column value to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,332 @@
+package pmo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// ConfigEnv is environment variable with path to configuration file
+const ConfigEnv = "PMOCLIENT_CONFIG"
+
+// configFileName is a name of configuration file in config directory without extension
+const configFileName = "pmoclient"
+
+// ConfigSources maps configuration field, e.g. `Spreadsheet.SecretFile`, to the
+// source of its effective value: default, config file or environment variable.
+type ConfigSources map[string]string
+
+// ConfigPath returns path of configuration file. Explicit path has priority,
+// then $PMOCLIENT_CONFIG, $XDG_CONFIG_HOME/pmoclient.* and ~/.config/pmoclient.*.
+// Second value reports whether path was requested explicitly and must exist.
+func ConfigPath(path string) (string, bool) {
+	if path != "" {
+		return path, true
+	}
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return path, true
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return findConfigFile(dir), false
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return findConfigFile(filepath.Join(home, ".config")), false
+	}
+	if usr, err := user.Current(); err == nil {
+		return findConfigFile(filepath.Join(usr.HomeDir, ".config")), false
+	}
+	return "", false
+}
+
+// findConfigFile returns pmoclient config file with any supported extension in dir.
+// Defaults to pmoclient.json if there is no config file.
+func findConfigFile(dir string) string {
+	for _, ext := range configExtensions {
+		path := filepath.Join(dir, configFileName+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, configFileName+configExtensions[0])
+}
+
+// LoadConfig reads configuration from file found by ConfigPath, selects profile and
+// applies environment overrides. Empty profile means `defaultProfile` from the file.
+// Missing default config file is not an error: configuration may be defined by environment only.
+func LoadConfig(path string, profile string) (Configuration, ConfigSources, error) {
+	file, fileSource, err := readConfigFile(path)
+	if err != nil {
+		return Configuration{}, ConfigSources{}, err
+	}
+	return selectProfile(file, fileSource, profile)
+}
+
+// LoadProfiles loads configuration for every profile in names. Empty names means all
+// profiles defined in config file.
+func LoadProfiles(path string, names []string) ([]Configuration, error) {
+	file, fileSource, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		names = file.ProfileNames()
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: no profiles defined in %v", ErrConfig, fileSource)
+	}
+
+	configs := make([]Configuration, 0, len(names))
+	for _, name := range names {
+		config, _, err := selectProfile(file, fileSource, name)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+// ProfileNames returns names of profiles defined in configuration in sorted order
+func (config Configuration) ProfileNames() []string {
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readRawConfig reads content of configuration file found by ConfigPath and converts it to JSON.
+// Returns nil content if default config file does not exist.
+func readRawConfig(path string) ([]byte, string, error) {
+	configPath, explicit := ConfigPath(path)
+	if configPath == "" {
+		return nil, "", nil
+	}
+	format, err := ConfigFormat(configPath)
+	if err != nil {
+		return nil, configPath, err
+	}
+
+	raw, err := ioutil.ReadFile(configPath) // nolint: gosec
+	switch {
+	case err == nil:
+		raw, err = configToJSON(raw, format)
+		if err != nil {
+			return nil, configPath, fmt.Errorf("%v: %w", configPath, err)
+		}
+		return raw, configPath, nil
+	case os.IsNotExist(err) && !explicit:
+		// no config file, rely on environment
+		return nil, configPath, nil
+	default:
+		return nil, configPath, fmt.Errorf("%w: reading config file %v: %v", ErrConfig, configPath, err)
+	}
+}
+
+// readConfigFile reads configuration file found by ConfigPath. Unknown fields are rejected.
+// Returns decoded file and its description for ConfigSources.
+func readConfigFile(path string) (Configuration, string, error) {
+	config := Configuration{}
+	raw, configPath, err := readRawConfig(path)
+	if err != nil || raw == nil {
+		return config, "", err
+	}
+
+	if problems := unknownFields(raw, reflect.TypeOf(config), "", false); len(problems) > 0 {
+		for i := range problems {
+			problems[i] = configPath + ": " + problems[i]
+		}
+		return config, "", problemsError(problems)
+	}
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return config, "", fmt.Errorf("%w: unmarshal config file %v: %v", ErrConfig, configPath, err)
+	}
+	if warning := permissionWarning(configPath, config); warning != "" {
+		log.Printf("[WARN] %s", warning)
+	}
+	return config, "file " + configPath, nil
+}
+
+// selectProfile overlays profile name on top-level values of the config file and applies
+// environment overrides.
+func selectProfile(file Configuration, fileSource string, name string) (Configuration, ConfigSources, error) {
+	sources := ConfigSources{}
+	config := file
+	config.Profiles = nil
+	config.DefaultProfile = ""
+	recordSources(reflect.ValueOf(config), "", fileSource, sources)
+
+	if name == "" {
+		name = file.DefaultProfile
+	}
+	if name != "" {
+		profile, ok := file.Profiles[name]
+		if !ok {
+			return config, sources, fmt.Errorf("%w: unknown profile %q, available: %v",
+				ErrConfig, name, strings.Join(file.ProfileNames(), ", "))
+		}
+		overlay(reflect.ValueOf(&config).Elem(), reflect.ValueOf(profile), "", fileSource+" profile "+name, sources)
+		config.Name = name
+	}
+
+	applyEnv(reflect.ValueOf(&config).Elem(), "", sources)
+	return config, sources, nil
+}
+
+// skipField reports whether field is not a configuration value: profiles and profile name
+func skipField(field reflect.StructField) bool {
+	return field.Tag.Get("env") == "-"
+}
+
+// recordSources records fileSource for every non-zero field of v, default for others
+func recordSources(v reflect.Value, prefix string, fileSource string, sources ConfigSources) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := prefix + field.Name
+		switch {
+		case skipField(field):
+		case field.Type.Kind() == reflect.Struct:
+			recordSources(v.Field(i), name+".", fileSource, sources)
+		case fileSource != "" && !v.Field(i).IsZero():
+			sources[name] = fileSource
+		default:
+			sources[name] = "default"
+		}
+	}
+}
+
+// overlay copies non-zero fields of src to dst
+func overlay(dst reflect.Value, src reflect.Value, prefix string, source string, sources ConfigSources) {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := prefix + field.Name
+		switch {
+		case skipField(field):
+		case field.Type.Kind() == reflect.Struct:
+			overlay(dst.Field(i), src.Field(i), name+".", source, sources)
+		case !src.Field(i).IsZero():
+			dst.Field(i).Set(src.Field(i))
+			sources[name] = source
+		}
+	}
+}
+
+// applyEnv overrides fields of struct v by environment variables defined in `env` tags
+func applyEnv(v reflect.Value, prefix string, sources ConfigSources) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		name := prefix + field.Name
+
+		if skipField(field) {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			applyEnv(value, name+".", sources)
+			continue
+		}
+
+		envName := field.Tag.Get("env")
+		envValue, ok := os.LookupEnv(envName)
+		if envName == "" || !ok {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.String:
+			value.SetString(envValue)
+		case reflect.Slice:
+			value.Set(reflect.ValueOf(splitList(envValue)))
+		case reflect.Map:
+			value.Set(reflect.ValueOf(splitMap(envValue)))
+		default:
+			continue
+		}
+		sources[name] = "env " + envName
+	}
+}
+
+// PrintConfig prints effective configuration values with their sources. Password is masked.
+func PrintConfig(w io.Writer, config Configuration, sources ConfigSources) error {
+	values := map[string]string{}
+	fields := configValues(reflect.ValueOf(config), "", values)
+
+	tw := tabwriter.NewWriter(w, 5, 0, 1, ' ', 0)
+	if config.Name != "" {
+		if _, err := fmt.Fprintf(tw, "Profile\t%s\t\n", config.Name); err != nil {
+			return fmt.Errorf("%w: failed to print profile: %v", ErrOutput, err)
+		}
+	}
+	for _, field := range fields {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", field, values[field], sources[field]); err != nil {
+			return fmt.Errorf("%w: failed to print config field %v: %v", ErrOutput, field, err)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("%w: can not flush tabwriter: %v", ErrOutput, err)
+	}
+	return nil
+}
+
+// configValues collects string representation of configuration fields into values.
+// Returns field names in order of declaration.
+func configValues(v reflect.Value, prefix string, values map[string]string) []string {
+	var fields []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := prefix + field.Name
+		if skipField(field) {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			fields = append(fields, configValues(v.Field(i), name+".", values)...)
+			continue
+		}
+		fields = append(fields, name)
+		switch value := v.Field(i); {
+		case field.Name == "Password" && !value.IsZero():
+			values[name] = "********"
+		case field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Map:
+			values[name] = fmt.Sprintf("%d entries", value.Len())
+		default:
+			values[name] = fmt.Sprint(value.Interface())
+		}
+	}
+	return fields
+}
+
+// splitMap splits comma-separated list of `key=value` pairs. Elements without `=` are ignored.
+func splitMap(s string) map[string]string {
+	result := map[string]string{}
+	for _, element := range splitList(s) {
+		parts := strings.SplitN(element, "=", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) != "" {
+			result[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return result
+}
+
+// splitList splits comma-separated list and trims spaces around elements
+func splitList(s string) []string {
+	result := []string{}
+	for _, element := range strings.Split(s, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			result = append(result, element)
+		}
+	}
+	return result
+}
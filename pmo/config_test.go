@@ -0,0 +1,283 @@
+package pmo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setEnv clears PMOCLIENT_* and XDG_CONFIG_HOME variables and sets env. Returned function restores environment.
+func setEnv(t *testing.T, env map[string]string) func() {
+	t.Helper()
+	saved := os.Environ()
+	for _, kv := range saved {
+		name := strings.SplitN(kv, "=", 2)[0]
+		if strings.HasPrefix(name, "PMOCLIENT_") || name == "XDG_CONFIG_HOME" {
+			_ = os.Unsetenv(name)
+		}
+	}
+	for name, value := range env {
+		if err := os.Setenv(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return func() {
+		os.Clearenv()
+		for _, kv := range saved {
+			parts := strings.SplitN(kv, "=", 2)
+			_ = os.Setenv(parts[0], parts[1])
+		}
+	}
+}
+
+// configDir creates temporary directory with files. Returned function removes it.
+func configDir(t *testing.T, files map[string]string) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "pmoclient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, func() { _ = os.RemoveAll(dir) }
+}
+
+func TestConfigPath(t *testing.T) {
+	xdg, remove := configDir(t, map[string]string{"pmoclient.yaml": "username: u\n", "pmoclient.toml": ""})
+	defer remove()
+	empty, removeEmpty := configDir(t, nil)
+	defer removeEmpty()
+
+	tests := []struct {
+		name         string
+		path         string
+		env          map[string]string
+		want         string
+		wantExplicit bool
+	}{
+		{"flag", "/etc/pmo.json", map[string]string{ConfigEnv: "/env/pmo.json"}, "/etc/pmo.json", true},
+		{"environment", "", map[string]string{ConfigEnv: "/env/pmo.json", "XDG_CONFIG_HOME": xdg}, "/env/pmo.json", true},
+		{"XDG file in lookup order", "", map[string]string{"XDG_CONFIG_HOME": xdg},
+			filepath.Join(xdg, "pmoclient.yaml"), false},
+		{"XDG without file", "", map[string]string{"XDG_CONFIG_HOME": empty},
+			filepath.Join(empty, "pmoclient.json"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setEnv(t, tt.env)()
+			got, explicit := ConfigPath(tt.path)
+			if got != tt.want || explicit != tt.wantExplicit {
+				t.Errorf("ConfigPath(%q) = %q, %v, want %q, %v", tt.path, got, explicit, tt.want, tt.wantExplicit)
+			}
+		})
+	}
+}
+
+func TestLoadConfigEnvironment(t *testing.T) {
+	dir, remove := configDir(t, map[string]string{"pmoclient.json": `{
+		"username": "file-user",
+		"loginUrl": "https://pmo/login",
+		"filterUsers": ["Ivan Petrenko"],
+		"Spreadsheet": {"SpreadsheetID": "sheet", "SecretFile": "file-secret.json"}
+	}`})
+	defer remove()
+	defer setEnv(t, map[string]string{
+		"XDG_CONFIG_HOME":                   dir,
+		"PMOCLIENT_USERNAME":                "env-user",
+		"PMOCLIENT_FILTER_USERS":            " Anna Shevchenko, ,ipetrenko ",
+		"PMOCLIENT_ALIASES":                 "Boss=1, Anna S. = ashevchenko2, broken",
+		"PMOCLIENT_SPREADSHEET_SECRET_FILE": "env-secret.json",
+	})()
+
+	config, sources, err := LoadConfig("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Configuration{
+		Username:    "env-user",
+		LoginURL:    "https://pmo/login",
+		FilterUsers: []string{"Anna Shevchenko", "ipetrenko"},
+		Aliases:     map[string]string{"Boss": "1", "Anna S.": "ashevchenko2"},
+		Spreadsheet: EngineersSpreadsheet{SpreadsheetID: "sheet", SecretFile: "env-secret.json"},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("config = %+v, want %+v", config, want)
+	}
+
+	file := "file " + filepath.Join(dir, "pmoclient.json")
+	for field, source := range map[string]string{
+		"Username":                  "env PMOCLIENT_USERNAME",
+		"LoginURL":                  file,
+		"PeopleListURL":             "default",
+		"FilterUsers":               "env PMOCLIENT_FILTER_USERS",
+		"Aliases":                   "env PMOCLIENT_ALIASES",
+		"Spreadsheet.SpreadsheetID": file,
+		"Spreadsheet.SecretFile":    "env PMOCLIENT_SPREADSHEET_SECRET_FILE",
+	} {
+		if sources[field] != source {
+			t.Errorf("source of %s is %q, want %q", field, sources[field], source)
+		}
+	}
+}
+
+func TestLoadConfigWithoutFile(t *testing.T) {
+	dir, remove := configDir(t, nil)
+	defer remove()
+	defer setEnv(t, map[string]string{"XDG_CONFIG_HOME": dir, "PMOCLIENT_LOGIN_URL": "https://pmo/login"})()
+
+	config, _, err := LoadConfig("", "")
+	if err != nil {
+		t.Fatalf("missing default config file is an error: %v", err)
+	}
+	if config.LoginURL != "https://pmo/login" {
+		t.Errorf("LoginURL = %q, want value from environment", config.LoginURL)
+	}
+	if _, _, err := LoadConfig(filepath.Join(dir, "missing.json"), ""); err == nil {
+		t.Error("missing explicit config file is not an error")
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", []string{}},
+		{"a", []string{"a"}},
+		{" a , b,,c ", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := splitList(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitList(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitMap(t *testing.T) {
+	tests := []struct {
+		value string
+		want  map[string]string
+	}{
+		{"", map[string]string{}},
+		{"a=1", map[string]string{"a": "1"}},
+		{" a = 1 , b=x=y, c=, noValue, =2", map[string]string{"a": "1", "b": "x=y", "c": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := splitMap(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitMap(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// profilesConfig is config file with two profiles used by profile tests
+const profilesConfig = `{
+	"username": "shared-user",
+	"loginUrl": "https://pmo/login",
+	"peopleListUrl": "https://pmo/people",
+	"filterUsers": ["Ivan Petrenko"],
+	"defaultProfile": "prod",
+	"profiles": {
+		"prod": {},
+		"staging": {
+			"loginUrl": "https://pmo-staging/login",
+			"peopleListUrl": "https://pmo-staging/people",
+			"filterUsers": ["Anna Shevchenko"]
+		}
+	}
+}`
+
+func TestLoadConfigProfiles(t *testing.T) {
+	dir, remove := configDir(t, map[string]string{"pmoclient.json": profilesConfig})
+	defer remove()
+	file := "file " + filepath.Join(dir, "pmoclient.json")
+
+	tests := []struct {
+		name        string
+		profile     string
+		env         map[string]string
+		wantName    string
+		wantLogin   string
+		wantFilter  []string
+		loginSource string
+	}{
+		{"default profile", "", nil, "prod", "https://pmo/login", []string{"Ivan Petrenko"}, file},
+		{"profile overlay", "staging", nil, "staging", "https://pmo-staging/login", []string{"Anna Shevchenko"},
+			file + " profile staging"},
+		{"environment over profile", "staging", map[string]string{"PMOCLIENT_LOGIN_URL": "https://env/login"},
+			"staging", "https://env/login", []string{"Anna Shevchenko"}, "env PMOCLIENT_LOGIN_URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"XDG_CONFIG_HOME": dir}
+			for name, value := range tt.env {
+				env[name] = value
+			}
+			defer setEnv(t, env)()
+
+			config, sources, err := LoadConfig("", tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.Name != tt.wantName || config.LoginURL != tt.wantLogin ||
+				!reflect.DeepEqual(config.FilterUsers, tt.wantFilter) {
+				t.Errorf("config is %q %q %q, want %q %q %q", config.Name, config.LoginURL, config.FilterUsers,
+					tt.wantName, tt.wantLogin, tt.wantFilter)
+			}
+			if config.Username != "shared-user" || config.Profiles != nil || config.DefaultProfile != "" {
+				t.Errorf("top-level values are not inherited or profiles are kept: %+v", config)
+			}
+			if sources["LoginURL"] != tt.loginSource {
+				t.Errorf("source of LoginURL is %q, want %q", sources["LoginURL"], tt.loginSource)
+			}
+		})
+	}
+
+	defer setEnv(t, map[string]string{"XDG_CONFIG_HOME": dir})()
+	if _, _, err := LoadConfig("", "qa"); err == nil || !strings.Contains(err.Error(), "prod, staging") {
+		t.Errorf("unknown profile error %v does not list available profiles", err)
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	dir, remove := configDir(t, map[string]string{"pmoclient.json": profilesConfig, "single.json": `{"username": "u"}`})
+	defer remove()
+	defer setEnv(t, map[string]string{"XDG_CONFIG_HOME": dir})()
+
+	tests := []struct {
+		name    string
+		path    string
+		names   []string
+		want    []string
+		wantErr bool
+	}{
+		{"all profiles", "", nil, []string{"prod", "staging"}, false},
+		{"selected", "", []string{"staging"}, []string{"staging"}, false},
+		{"unknown", "", []string{"staging", "qa"}, nil, true},
+		{"config without profiles", filepath.Join(dir, "single.json"), nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, err := LoadProfiles(tt.path, tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadProfiles error = %v, want error %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, config := range configs {
+				got = append(got, config.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadProfiles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
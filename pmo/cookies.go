@@ -0,0 +1,230 @@
+package pmo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// savedCookie is a cookie stored in session file with all attributes set by PMO
+type savedCookie struct {
+	// URL is address of response that set the cookie. Host-only cookies and cookies without path depend on it.
+	URL      string    `json:"url"`
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"httpOnly,omitempty"`
+	Expires  time.Time `json:"expires"` // zero for session cookies
+}
+
+// savedSession is a content of session file
+type savedSession struct {
+	Cookies []savedCookie `json:"cookies"`
+}
+
+// sessionJar is cookie jar which remembers cookies set by PMO with their attributes,
+// so they can be saved to session file. Jar of net/http returns names and values only.
+type sessionJar struct {
+	jar     http.CookieJar
+	mu      sync.Mutex
+	cookies map[string]savedCookie // by name, domain and path
+	changed bool                   // cookies are set since the last save
+}
+
+// newSessionJar returns empty session jar
+func newSessionJar() *sessionJar {
+	jar, _ := cookiejar.New(nil)
+	return &sessionJar{jar: jar, cookies: map[string]savedCookie{}}
+}
+
+// SetCookies stores cookies set in response to u
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	for _, c := range cookies {
+		saved := savedCookie{URL: u.String(), Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path,
+			Secure: c.Secure, HttpOnly: c.HttpOnly, Expires: c.Expires}
+		if c.MaxAge > 0 {
+			saved.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		key := c.Name + ";" + strings.ToLower(c.Domain) + ";" + c.Path
+		if c.MaxAge < 0 || saved.expired(now) {
+			delete(j.cookies, key)
+		} else {
+			j.cookies[key] = saved
+		}
+		j.changed = true
+	}
+}
+
+// Cookies returns cookies to send in request to u
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// restore puts saved cookies into jar. Expired cookies are dropped. Returns number of restored cookies.
+func (j *sessionJar) restore(saved []savedCookie) int {
+	now := time.Now()
+	restored := 0
+	for _, c := range saved {
+		u, err := url.Parse(c.URL)
+		if err != nil || c.expired(now) {
+			continue
+		}
+		j.SetCookies(u, []*http.Cookie{{Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path,
+			Secure: c.Secure, HttpOnly: c.HttpOnly, Expires: c.Expires}})
+		restored++
+	}
+	j.mu.Lock()
+	j.changed = false
+	j.mu.Unlock()
+	return restored
+}
+
+// saved returns cookies to save if they are changed since the last call
+func (j *sessionJar) saved() ([]savedCookie, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.changed {
+		return nil, false
+	}
+	j.changed = false
+	now := time.Now()
+	cookies := make([]savedCookie, 0, len(j.cookies))
+	for _, c := range j.cookies {
+		if !c.expired(now) {
+			cookies = append(cookies, c)
+		}
+	}
+	sort.Slice(cookies, func(i, k int) bool {
+		a, b := cookies[i], cookies[k]
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Name < b.Name
+	})
+	return cookies, true
+}
+
+// expired reports whether cookie expires before now. Session cookies never expire in session file.
+func (c savedCookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && c.Expires.Before(now)
+}
+
+// sessionDir returns directory to store PMO sessions
+func sessionDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: can not detect cache directory: %v", ErrConfig, err)
+	}
+	return filepath.Join(dir, "pmoclient"), nil
+}
+
+// DefaultSessionFile returns path of the file used to persist PMO session of the profile between runs.
+func DefaultSessionFile(profile string) (string, error) {
+	dir, err := sessionDir()
+	if err != nil {
+		return "", err
+	}
+	if profile == "" {
+		return filepath.Join(dir, "session.json"), nil
+	}
+	return filepath.Join(dir, "session-"+safeFileName(profile)+".json"), nil
+}
+
+// safeFileName replaces characters of name other than ASCII letters, digits, dot, dash and underscore with dash,
+// so profile names can be used in file names
+func safeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, name)
+}
+
+// UseSessionFile loads session cookies saved in path by previous runs. Cookies set by PMO on login
+// and later requests are saved to the same file. Missing file is not an error.
+func (pmo *PMO) UseSessionFile(path string) error {
+	pmo.sessionFile = path
+
+	raw, err := ioutil.ReadFile(path) // nolint: gosec
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("can not read session file %v: %w", path, err)
+	}
+
+	var session savedSession
+	if err := json.Unmarshal(raw, &session); err != nil {
+		return fmt.Errorf("can not decode session file %v: %w", path, err)
+	}
+	pmo.hasSession = pmo.cookies.restore(session.Cookies) > 0
+	return nil
+}
+
+// HasSession reports whether session cookies were restored from session file.
+func (pmo *PMO) HasSession() bool {
+	return pmo.hasSession
+}
+
+// saveSession writes session cookies to the session file if PMO set any since the last save
+func (pmo *PMO) saveSession() error {
+	if pmo.sessionFile == "" {
+		return nil
+	}
+	cookies, changed := pmo.cookies.saved()
+	if !changed {
+		return nil
+	}
+
+	raw, err := json.Marshal(savedSession{Cookies: cookies})
+	if err != nil {
+		return fmt.Errorf("can not encode session: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(pmo.sessionFile), 0700); err != nil {
+		return fmt.Errorf("can not create session directory: %w", err)
+	}
+	if err := ioutil.WriteFile(pmo.sessionFile, raw, 0600); err != nil {
+		return fmt.Errorf("can not write session file %v: %w", pmo.sessionFile, err)
+	}
+	return nil
+}
+
+// RemoveSessions deletes saved sessions of all profiles
+func RemoveSessions() error {
+	dir, err := sessionDir()
+	if err != nil {
+		return err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "session*.json"))
+	if err != nil {
+		return fmt.Errorf("can not list session files: %w", err)
+	}
+	for _, path := range files {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("can not remove session file %v: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package pmo
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSessionFile(t *testing.T) {
+	// server sets session cookie on login and refreshes token cookie on people list request
+	mux := http.NewServeMux()
+	mux.HandleFunc("/app/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "s1", Path: "/", HttpOnly: true})
+		http.SetCookie(w, &http.Cookie{Name: "old", Value: "x", Path: "/", MaxAge: 3600})
+		http.Redirect(w, r, "/app/home", http.StatusFound)
+	})
+	mux.HandleFunc("/app/api/people", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "token", Value: "t2", Path: "/", Expires: time.Now().Add(time.Hour)})
+		http.SetCookie(w, &http.Cookie{Name: "old", Value: "", Path: "/", MaxAge: -1})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	})
+	var sent []*http.Cookie
+	mux.HandleFunc("/other/path", func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Cookies()
+		_, _ = w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := Configuration{Username: "user", Password: "secret",
+		LoginURL: server.URL + "/app/login", PeopleListURL: server.URL + "/app/api/people"}
+	dir, err := ioutil.TempDir("", "pmoclient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, "session.json")
+	ctx := context.Background()
+
+	first := NewPMO(config)
+	if err := first.UseSessionFile(file); err != nil {
+		t.Fatal(err)
+	}
+	if first.HasSession() {
+		t.Fatal("session is restored from missing file")
+	}
+	if err := first.Login(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.Engineers(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	second := NewPMO(config)
+	if err := second.UseSessionFile(file); err != nil {
+		t.Fatal(err)
+	}
+	if !second.HasSession() {
+		t.Fatal("session is not restored")
+	}
+	if _, err := second.get(ctx, server.URL+"/other/path"); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, c := range sent {
+		got[c.Name] = c.Value
+	}
+	// Path=/ cookies set on login and people list URLs are sent to any path, deleted cookie is not
+	want := map[string]string{"JSESSIONID": "s1", "token": "t2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("restored session sent cookies %v, want %v", got, want)
+	}
+}
+
+func TestSessionJarRestore(t *testing.T) {
+	saved := []savedCookie{
+		{URL: "https://pmo.example.com/app/api/people", Name: "narrow", Value: "1"},
+		{URL: "https://pmo.example.com/app/api/people", Name: "root", Value: "2", Path: "/"},
+		{URL: "https://pmo.example.com/app/login", Name: "domain", Value: "3", Domain: "example.com", Path: "/"},
+		{URL: "https://pmo.example.com/app/login", Name: "expired", Value: "4", Path: "/",
+			Expires: time.Now().Add(-time.Hour)},
+	}
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"https://pmo.example.com/app/api/other", []string{"narrow", "root", "domain"}},
+		{"https://pmo.example.com/", []string{"root", "domain"}},
+		{"https://sso.example.com/", []string{"domain"}},
+		{"https://example.org/", nil},
+	}
+	jar := newSessionJar()
+	if restored := jar.restore(saved); restored != 3 {
+		t.Errorf("restored %d cookies, want 3", restored)
+	}
+	if _, changed := jar.saved(); changed {
+		t.Error("restored session is reported as changed")
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			var got []string
+			for _, c := range jar.Cookies(req.URL) {
+				got = append(got, c.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cookies for %s are %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package pmo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Environment variables used by EnvCredentials
+const (
+	UsernameEnv = "PMOCLIENT_USERNAME"
+	PasswordEnv = "PMOCLIENT_PASSWORD"
+)
+
+// Credentials used to login to PMO
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// CredentialsProvider is a source of PMO credentials.
+// Providers return ErrNoCredentials if they have nothing to offer.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// EnvCredentials reads credentials from PMOCLIENT_USERNAME and PMOCLIENT_PASSWORD.
+// Username falls back to Username field if variable is not set.
+type EnvCredentials struct {
+	Username string
+}
+
+// Credentials implements CredentialsProvider
+func (p EnvCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	password, ok := os.LookupEnv(PasswordEnv)
+	if !ok {
+		return Credentials{}, fmt.Errorf("%w: %s is not set", ErrNoCredentials, PasswordEnv)
+	}
+	username := p.Username
+	if u, ok := os.LookupEnv(UsernameEnv); ok {
+		username = u
+	}
+	return Credentials{Username: username, Password: password}, nil
+}
+
+// CommandCredentials gets password from the first line of command output,
+// e.g. `pass show pmo`. Command is executed by `sh -c`.
+type CommandCredentials struct {
+	Username string
+	Command  string
+}
+
+// Credentials implements CredentialsProvider
+func (p CommandCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	if p.Command == "" {
+		return Credentials{}, fmt.Errorf("%w: password command is not configured", ErrNoCredentials)
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", p.Command) // nolint: gosec
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return Credentials{}, fmt.Errorf("password command %q failed: %w", p.Command, err)
+	}
+	return Credentials{Username: p.Username, Password: firstLine(string(out))}, nil
+}
+
+// FileCredentials reads password from the first line of the file
+type FileCredentials struct {
+	Username string
+	Path     string
+}
+
+// Credentials implements CredentialsProvider
+func (p FileCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	if p.Path == "" {
+		return Credentials{}, fmt.Errorf("%w: password file is not configured", ErrNoCredentials)
+	}
+	raw, err := ioutil.ReadFile(p.Path) // nolint: gosec
+	if err != nil {
+		return Credentials{}, fmt.Errorf("can not read password file: %w", err)
+	}
+	return Credentials{Username: p.Username, Password: firstLine(string(raw))}, nil
+}
+
+// StaticCredentials returns password stored in configuration file.
+// Using it prints warning: password should not be stored in plaintext.
+type StaticCredentials struct {
+	Username string
+	Password string
+}
+
+// Credentials implements CredentialsProvider
+func (p StaticCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	if p.Password == "" {
+		return Credentials{}, fmt.Errorf("%w: password is not set in config", ErrNoCredentials)
+	}
+	log.Printf("[WARN] using plaintext password from config file. Consider %s, passwordCommand or passwordFile instead", PasswordEnv)
+	return Credentials{Username: p.Username, Password: p.Password}, nil
+}
+
+// PromptCredentials asks for username and password in terminal. Password is not echoed.
+type PromptCredentials struct {
+	Username string
+}
+
+// Credentials implements CredentialsProvider
+func (p PromptCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return Credentials{}, fmt.Errorf("%w: stdin is not a terminal", ErrNoCredentials)
+	}
+
+	username := p.Username
+	if username == "" {
+		fmt.Fprint(os.Stderr, "PMO username: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return Credentials{}, fmt.Errorf("can not read username: %w", err)
+		}
+		username = strings.TrimSpace(line)
+	}
+
+	fmt.Fprintf(os.Stderr, "PMO password for %s: ", username)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("can not read password: %w", err)
+	}
+	return Credentials{Username: username, Password: string(password)}, nil
+}
+
+// ChainCredentials tries providers in order and returns the first credentials found.
+type ChainCredentials []CredentialsProvider
+
+// Credentials implements CredentialsProvider
+func (chain ChainCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	for _, provider := range chain {
+		creds, err := provider.Credentials(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return creds, err
+	}
+	return Credentials{}, fmt.Errorf("%w: set %s, passwordCommand or passwordFile in config", ErrNoCredentials, PasswordEnv)
+}
+
+// CredentialsFromConfig returns default chain of providers for configuration:
+// environment, password command, password file, password from config and terminal prompt.
+func CredentialsFromConfig(config Configuration) CredentialsProvider {
+	return ChainCredentials{
+		EnvCredentials{Username: config.Username},
+		CommandCredentials{Username: config.Username, Command: config.PasswordCommand},
+		FileCredentials{Username: config.Username, Path: config.PasswordFile},
+		StaticCredentials{Username: config.Username, Password: config.Password},
+		PromptCredentials{Username: config.Username},
+	}
+}
+
+// firstLine returns first line of s without trailing whitespace
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimRight(s, "\r \t")
+}
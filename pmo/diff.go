@@ -0,0 +1,324 @@
+package pmo
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of changes between two people lists
+const (
+	ChangeAdded             = "added"
+	ChangeRemoved           = "removed"
+	ChangeField             = "changed"
+	ChangeAssignmentNew     = "assignment new"
+	ChangeAssignmentEnded   = "assignment ended"
+	ChangeAssignmentChanged = "assignment changed"
+	ChangeBench             = "bench"
+)
+
+// changeKinds lists kinds of changes in order of changelog sections with section titles
+var changeKinds = []struct{ kind, title string }{
+	{ChangeAdded, "Added"},
+	{ChangeRemoved, "Removed"},
+	{ChangeField, "Changed"},
+	{ChangeAssignmentNew, "New assignments"},
+	{ChangeAssignmentEnded, "Ended assignments"},
+	{ChangeAssignmentChanged, "Changed assignments"},
+	{ChangeBench, "Bench"},
+}
+
+// Change is difference of engineer between two people lists
+type Change struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Source   string `json:"source,omitempty"`
+	Kind     string `json:"kind"`
+	// Field is changed person field for ChangeField
+	Field string `json:"field,omitempty"`
+	// From and To are old and new values of field, assignment or bench status
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// Assignment is ID of assignment for assignment changes
+	Assignment int    `json:"assignment,omitempty"`
+	Details    string `json:"details"`
+}
+
+// DiffSide is people list at some moment
+type DiffSide struct {
+	Engineers []Person
+	Time      time.Time
+}
+
+// diffFields are person fields compared by Diff
+var diffFields = []struct {
+	name  string
+	value func(*Person) string
+}{
+	{"grade", func(p *Person) string { return p.Grade }},
+	{"profile", func(p *Person) string { return p.Profile }},
+	{"position", func(p *Person) string { return p.Position }},
+	{"location", func(p *Person) string { return p.Location }},
+	{"manager", func(p *Person) string { return p.Manager }},
+}
+
+// Diff returns changes from people list `from` to `to`: added and removed engineers, changes of grade, profile,
+// position, location and manager, new, ended and changed assignments, and bench transitions. Engineer is on bench
+// if total involvement at the time of the list is below benchBelow percent. If where is not nil, only engineers
+// matching it in either list are compared, so engineer moved out of selection is reported as changed, not removed.
+// Changes are sorted by kind and name.
+func Diff(from DiffSide, to DiffSide, benchBelow float64, where Predicate) []Change {
+	selected := func(engineers ...*Person) bool {
+		for _, engineer := range engineers {
+			if where == nil || (engineer != nil && where(engineer)) {
+				return true
+			}
+		}
+		return false
+	}
+	old := make(map[string]*Person, len(from.Engineers))
+	for i := range from.Engineers {
+		old[personKey(&from.Engineers[i])] = &from.Engineers[i]
+	}
+
+	changes := []Change{}
+	seen := make(map[string]bool, len(to.Engineers))
+	for i := range to.Engineers {
+		engineer := &to.Engineers[i]
+		key := personKey(engineer)
+		seen[key] = true
+		previous, ok := old[key]
+		if !selected(previous, engineer) {
+			continue
+		}
+		if !ok {
+			changes = append(changes, personChange(engineer, ChangeAdded, describePerson(engineer)))
+			continue
+		}
+		changes = append(changes, diffPerson(previous, engineer, from.Time, to.Time, benchBelow)...)
+	}
+	for i := range from.Engineers {
+		engineer := &from.Engineers[i]
+		if !seen[personKey(engineer)] && selected(engineer) {
+			changes = append(changes, personChange(engineer, ChangeRemoved, describePerson(engineer)))
+		}
+	}
+
+	order := make(map[string]int, len(changeKinds))
+	for i, k := range changeKinds {
+		order[k.kind] = i
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return order[changes[i].Kind] < order[changes[j].Kind]
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// personKey identifies engineer in both lists: by ID or by username if ID is unknown
+func personKey(p *Person) string {
+	if p.ID != 0 {
+		return strconv.Itoa(p.ID)
+	}
+	return "username:" + strings.ToLower(p.Username)
+}
+
+// personChange returns change of kind for engineer p
+func personChange(p *Person, kind string, details string) Change {
+	return Change{Name: p.Name, Username: p.Username, Source: p.Source, Kind: kind, Details: details}
+}
+
+// describePerson returns short description of engineer: grade, position and location
+func describePerson(p *Person) string {
+	var parts []string
+	for _, part := range []string{p.Grade, p.Position, p.Location} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diffPerson returns changes of the same engineer
+func diffPerson(from *Person, to *Person, fromTime time.Time, toTime time.Time, benchBelow float64) []Change {
+	var changes []Change
+	for _, field := range diffFields {
+		a, b := field.value(from), field.value(to)
+		if a == b {
+			continue
+		}
+		change := personChange(to, ChangeField, fmt.Sprintf("%s: %s → %s", field.name, orNone(a), orNone(b)))
+		change.Field, change.From, change.To = field.name, a, b
+		changes = append(changes, change)
+	}
+
+	changes = append(changes, diffAssignments(to, from.Assignments, to.Assignments, fromTime, toTime)...)
+
+	before, after := from.AsOf(fromTime), to.AsOf(toTime)
+	wasOnBench, isOnBench := before.TotalInvolvement() < benchBelow, after.TotalInvolvement() < benchBelow
+	if wasOnBench != isOnBench {
+		details := fmt.Sprintf("left bench, involvement %g%% → %g%%", before.TotalInvolvement(), after.TotalInvolvement())
+		if isOnBench {
+			details = fmt.Sprintf("on bench, involvement %g%% → %g%%", before.TotalInvolvement(), after.TotalInvolvement())
+		}
+		change := personChange(to, ChangeBench, details)
+		change.From, change.To = benchStatus(wasOnBench), benchStatus(isOnBench)
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// benchStatus returns bench status for change
+func benchStatus(onBench bool) string {
+	if onBench {
+		return "bench"
+	}
+	return "assigned"
+}
+
+// diffAssignments returns changes of assignments of engineer p. Assignment is ended if it is removed
+// or if it was active at fromTime and finished before toTime.
+func diffAssignments(p *Person, from []Assignment, to []Assignment, fromTime time.Time, toTime time.Time) []Change {
+	old := make(map[string]Assignment, len(from))
+	for _, a := range from {
+		old[assignmentKey(a)] = a
+	}
+
+	var changes []Change
+	seen := make(map[string]bool, len(to))
+	for _, a := range to {
+		key := assignmentKey(a)
+		seen[key] = true
+		previous, ok := old[key]
+		if !ok {
+			changes = append(changes, assignmentChange(p, ChangeAssignmentNew, a.ID, "", describeAssignment(a),
+				describeAssignment(a)))
+			continue
+		}
+		differences := assignmentDifferences(previous, a)
+		if previous.ActiveOn(fromTime) && !a.Finish.IsZero() && a.Finish.Before(Day(toTime)) {
+			details := strings.Join(append([]string{"finished " + FormatDate(a.Finish)}, differences...), "; ")
+			changes = append(changes, assignmentChange(p, ChangeAssignmentEnded, a.ID, describeAssignment(previous),
+				describeAssignment(a), details))
+			continue
+		}
+		if len(differences) > 0 {
+			changes = append(changes, assignmentChange(p, ChangeAssignmentChanged, a.ID, describeAssignment(previous),
+				describeAssignment(a), strings.Join(differences, "; ")))
+		}
+	}
+	for _, a := range from {
+		if !seen[assignmentKey(a)] {
+			changes = append(changes, assignmentChange(p, ChangeAssignmentEnded, a.ID, describeAssignment(a), "",
+				"removed from PMO"))
+		}
+	}
+	return changes
+}
+
+// assignmentKey identifies assignment in both lists: by ID or by account, project and start if ID is unknown
+func assignmentKey(a Assignment) string {
+	if a.ID != 0 {
+		return strconv.Itoa(a.ID)
+	}
+	return fmt.Sprintf("%s|%s|%s", a.Account, a.Project, FormatDate(a.Start))
+}
+
+// assignmentChange returns change of kind for assignment with id of engineer p
+func assignmentChange(p *Person, kind string, id int, from string, to string, details string) Change {
+	change := personChange(p, kind, details)
+	change.Assignment, change.From, change.To = id, from, to
+	return change
+}
+
+// describeAssignment returns assignment as `account/project involvement% start..finish status`
+func describeAssignment(a Assignment) string {
+	return fmt.Sprintf("%s/%s %g%% %s..%s %s", a.Account, a.Project, a.Involvement,
+		FormatDate(a.Start), FormatDate(a.Finish), a.Status)
+}
+
+// assignmentDifferences returns changes of involvement, dates and status of the same assignment
+func assignmentDifferences(from Assignment, to Assignment) []string {
+	var differences []string
+	if from.Involvement != to.Involvement {
+		differences = append(differences, fmt.Sprintf("involvement %g%% → %g%%", from.Involvement, to.Involvement))
+	}
+	if !from.Start.Equal(to.Start) {
+		differences = append(differences, fmt.Sprintf("start %s → %s", orNone(FormatDate(from.Start)),
+			orNone(FormatDate(to.Start))))
+	}
+	if !from.Finish.Equal(to.Finish) {
+		differences = append(differences, fmt.Sprintf("finish %s → %s", orNone(FormatDate(from.Finish)),
+			orNone(FormatDate(to.Finish))))
+	}
+	if from.Status != to.Status {
+		differences = append(differences, fmt.Sprintf("status %s → %s", orNone(from.Status), orNone(to.Status)))
+	}
+	return differences
+}
+
+// orNone returns value or `none` if it is empty
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// ChangesTable returns changes as table
+func ChangesTable(changes []Change) Table {
+	table := Table{Header: []string{"Name", "Username", "Kind", "Assignment", "Details"}}
+	sources := make([]string, 0, len(changes))
+	for _, c := range changes {
+		assignment := ""
+		if c.Assignment != 0 {
+			assignment = strconv.Itoa(c.Assignment)
+		}
+		table.Rows = append(table.Rows, []interface{}{c.Name, c.Username, c.Kind, assignment, c.Details})
+		sources = append(sources, c.Source)
+	}
+	table.addSourceColumn(sources)
+	return table
+}
+
+// WriteChangelog writes changes as human readable changelog grouped by kind under title
+func WriteChangelog(w io.Writer, title string, changes []Change) error {
+	if _, err := fmt.Fprintln(w, title); err != nil {
+		return wrapOutput(err)
+	}
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "\nNo changes")
+		return wrapOutput(err)
+	}
+	for _, k := range changeKinds {
+		var lines []string
+		for _, c := range changes {
+			if c.Kind != k.kind {
+				continue
+			}
+			name := c.Name
+			if c.Username != "" {
+				name = fmt.Sprintf("%s (%s)", c.Name, c.Username)
+			}
+			if c.Source != "" {
+				name = fmt.Sprintf("[%s] %s", c.Source, name)
+			}
+			if c.Kind == ChangeAssignmentChanged || c.Kind == ChangeAssignmentEnded {
+				name = fmt.Sprintf("%s, %s", name, c.From)
+			}
+			lines = append(lines, fmt.Sprintf("  - %s: %s", name, c.Details))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "\n%s (%d):\n%s\n", k.title, len(lines), strings.Join(lines, "\n")); err != nil {
+			return wrapOutput(err)
+		}
+	}
+	return nil
+}
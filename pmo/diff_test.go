@@ -0,0 +1,116 @@
+package pmo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffAssignments(t *testing.T) {
+	from, to := date(2026, 10, 11), date(2026, 10, 18)
+	type change struct {
+		kind       string
+		assignment int
+		details    string
+	}
+	tests := []struct {
+		name     string
+		old, new []Assignment
+		want     []change
+	}{
+		{"unchanged", []Assignment{assignment(1, "2026-01-01", "2026-12-31", 100)},
+			[]Assignment{assignment(1, "2026-01-01", "2026-12-31", 100)}, nil},
+		{"new", nil, []Assignment{assignment(2, "2026-11-01", "2026-12-31", 50)},
+			[]change{{ChangeAssignmentNew, 2, "/ 50% 2026-11-01..2026-12-31 Active"}}},
+		{"removed", []Assignment{assignment(1, "2026-01-01", "2026-12-31", 100)}, nil,
+			[]change{{ChangeAssignmentEnded, 1, "removed from PMO"}}},
+		{"finished between lists", []Assignment{assignment(1, "2026-01-01", "2026-10-15", 100)},
+			[]Assignment{assignment(1, "2026-01-01", "2026-10-15", 100)},
+			[]change{{ChangeAssignmentEnded, 1, "finished 2026-10-15"}}},
+		{"finished early", []Assignment{assignment(1, "2026-01-01", "2026-12-31", 100)},
+			[]Assignment{assignment(1, "2026-01-01", "2026-10-14", 100)},
+			[]change{{ChangeAssignmentEnded, 1, "finished 2026-10-14; finish 2026-12-31 → 2026-10-14"}}},
+		{"finishes on the day of new list", []Assignment{assignment(1, "2026-01-01", "2026-10-18", 100)},
+			[]Assignment{assignment(1, "2026-01-01", "2026-10-18", 100)}, nil},
+		{"finished before old list", []Assignment{assignment(1, "2026-01-01", "2026-09-30", 100)},
+			[]Assignment{assignment(1, "2026-01-01", "2026-09-30", 100)}, nil},
+		{"involvement and dates", []Assignment{assignment(1, "2026-01-01", "2026-12-31", 50)},
+			[]Assignment{assignment(1, "2026-02-01", "", 80)},
+			[]change{{ChangeAssignmentChanged, 1,
+				"involvement 50% → 80%; start 2026-01-01 → 2026-02-01; finish 2026-12-31 → none"}}},
+		{"status", []Assignment{{ID: 1, Status: "Proposed"}}, []Assignment{{ID: 1, Status: "Active"}},
+			[]change{{ChangeAssignmentChanged, 1, "status Proposed → Active"}}},
+		{"matched without ID", []Assignment{{Account: "Acme", Project: "Web", Start: date(2026, 1, 1), Involvement: 50}},
+			[]Assignment{{Account: "Acme", Project: "Web", Start: date(2026, 1, 1), Involvement: 100}},
+			[]change{{ChangeAssignmentChanged, 0, "involvement 50% → 100%"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []change
+			for _, c := range diffAssignments(&Person{Name: "Ivan"}, tt.old, tt.new, from, to) {
+				got = append(got, change{c.Kind, c.Assignment, c.Details})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffAssignments = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from := DiffSide{Time: date(2026, 10, 11), Engineers: []Person{
+		{ID: 1, Name: "Ivan", Grade: "SE2", Location: "Kyiv",
+			Assignments: []Assignment{assignment(11, "2026-01-01", "2026-10-15", 100)}},
+		{ID: 2, Name: "Anna", Grade: "SE3", Location: "Kyiv"},
+		{ID: 3, Name: "Oleksii", Location: "Lviv"},
+	}}
+	to := DiffSide{Time: date(2026, 10, 18), Engineers: []Person{
+		{ID: 1, Name: "Ivan", Grade: "SE3", Location: "Kyiv",
+			Assignments: []Assignment{assignment(11, "2026-01-01", "2026-10-15", 100)}},
+		{ID: 2, Name: "Anna", Grade: "SE3", Location: "Lviv",
+			Assignments: []Assignment{assignment(21, "2026-10-12", "", 100)}},
+		{ID: 4, Name: "Mykola", Location: "Kyiv"},
+	}}
+	kyiv, err := ParseFilter(`location=="Kyiv"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type change struct{ name, kind, field string }
+	tests := []struct {
+		name  string
+		where Predicate
+		want  []change
+	}{
+		{"everyone", nil, []change{
+			{"Mykola", ChangeAdded, ""},
+			{"Oleksii", ChangeRemoved, ""},
+			{"Anna", ChangeField, "location"},
+			{"Ivan", ChangeField, "grade"},
+			{"Anna", ChangeAssignmentNew, ""},
+			{"Ivan", ChangeAssignmentEnded, ""},
+			{"Anna", ChangeBench, ""},
+			{"Ivan", ChangeBench, ""},
+		}},
+		// Anna moved out of Kyiv and is still compared, Oleksii was never in Kyiv
+		{"where in either list", kyiv, []change{
+			{"Mykola", ChangeAdded, ""},
+			{"Anna", ChangeField, "location"},
+			{"Ivan", ChangeField, "grade"},
+			{"Anna", ChangeAssignmentNew, ""},
+			{"Ivan", ChangeAssignmentEnded, ""},
+			{"Anna", ChangeBench, ""},
+			{"Ivan", ChangeBench, ""},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []change
+			for _, c := range Diff(from, to, 100, tt.where) {
+				got = append(got, change{c.Name, c.Kind, c.Field})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package pmo
+
+import "errors"
+
+// Errors returned by the pmo package. They are wrapped with additional
+// context, so use errors.Is to check for them.
+var (
+	// ErrConfig is returned when configuration can not be read or parsed.
+	ErrConfig = errors.New("pmo: invalid configuration")
+	// ErrLoginFailed is returned when PMO rejects provided credentials or login request fails.
+	ErrLoginFailed = errors.New("pmo: login failed")
+	// ErrNoCredentials is returned when credentials provider has no credentials to offer.
+	ErrNoCredentials = errors.New("pmo: no credentials")
+	// ErrSessionExpired is returned when PMO session is not valid anymore and login is required.
+	ErrSessionExpired = errors.New("pmo: session expired")
+	// ErrRequest is returned when request to PMO can not be sent.
+	ErrRequest = errors.New("pmo: request failed")
+	// ErrUnexpectedStatus is returned when PMO responds with unexpected HTTP status.
+	ErrUnexpectedStatus = errors.New("pmo: unexpected response status")
+	// ErrDecode is returned when PMO response can not be decoded.
+	ErrDecode = errors.New("pmo: unable to decode response")
+	// ErrInvalidOption is returned when output or report option can not be parsed.
+	ErrInvalidOption = errors.New("pmo: invalid option")
+	// ErrUnmatched is returned in strict mode when filter entries do not match any engineer.
+	ErrUnmatched = errors.New("pmo: unmatched filter entries")
+	// ErrAllocations is returned when allocation check finds problems with assignments.
+	ErrAllocations = errors.New("pmo: allocation issues found")
+	// ErrSnapshot is returned when snapshot can not be saved, found or loaded.
+	ErrSnapshot = errors.New("pmo: snapshot error")
+	// ErrNoCache is returned when there is no cached people list or it is too old.
+	ErrNoCache = errors.New("pmo: no cached data")
+	// ErrOutput is returned when results can not be written.
+	ErrOutput = errors.New("pmo: unable to write output")
+)
@@ -0,0 +1,395 @@
+package pmo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Predicate reports whether engineer matches a filter
+type Predicate func(p *Person) bool
+
+// Select returns engineers matching predicate
+func Select(engineers []Person, predicate Predicate) []Person {
+	selected := make([]Person, 0)
+	for i := range engineers {
+		if predicate(&engineers[i]) {
+			selected = append(selected, engineers[i])
+		}
+	}
+	return selected
+}
+
+// And combines predicates. Nil predicates are skipped.
+func And(predicates ...Predicate) Predicate {
+	return func(p *Person) bool {
+		for _, predicate := range predicates {
+			if predicate != nil && !predicate(p) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// listFields are fields with several values. Comparison matches if any value matches.
+var listFields = map[string]func(p *Person) []string{
+	"account":          func(p *Person) []string { return p.GetAccounts() },
+	"accounts":         func(p *Person) []string { return p.GetAccounts() },
+	"project":          func(p *Person) []string { return p.GetProjects() },
+	"projects":         func(p *Person) []string { return p.GetProjects() },
+	"status":           func(p *Person) []string { return p.AssignmentStatuses() },
+	"statuses":         func(p *Person) []string { return p.AssignmentStatuses() },
+	"engineermanager":  func(p *Person) []string { return p.GetEngineerManagers() },
+	"engineermanagers": func(p *Person) []string { return p.GetEngineerManagers() },
+}
+
+// fieldValues returns accessor of field values by name
+func fieldValues(name string) (func(p *Person) []interface{}, error) {
+	if values, ok := listFields[strings.ToLower(name)]; ok {
+		return func(p *Person) []interface{} {
+			list := values(p)
+			result := make([]interface{}, 0, len(list))
+			for _, value := range list {
+				result = append(result, value)
+			}
+			return result
+		}, nil
+	}
+	column, err := ColumnByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return func(p *Person) []interface{} { return []interface{}{column.Value(p)} }, nil
+}
+
+// ParseFilter parses filter expression, e.g.
+// `location=="Kyiv" && grade in ["SE3","SE4"] && account~"Acme" && daysOnBench>10`.
+// Supported operators: == != < <= > >= ~ (case-insensitive regexp) !~ in, && || ! and parentheses.
+// Fields are column names; account, project, status and engineerManager match any of engineer's values.
+// String comparison is case-insensitive.
+func ParseFilter(expr string) (Predicate, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	predicate, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+	return predicate, nil
+}
+
+// token kinds
+const (
+	tokenEOF = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOp
+)
+
+type token struct {
+	kind int
+	text string
+	pos  int
+}
+
+// operators sorted so longer operators are matched first
+var operators = []string{"==", "!=", "<=", ">=", "!~", "&&", "||", "<", ">", "~", "!", "(", ")", "[", "]", ","}
+
+// lex splits expression to tokens
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	for pos := 0; pos < len(expr); {
+		r := rune(expr[pos])
+		switch {
+		case unicode.IsSpace(r):
+			pos++
+		case r == '"':
+			end := pos + 1
+			for ; end < len(expr) && expr[end] != '"'; end++ {
+				if expr[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("%w: filter: unterminated string at %d", ErrInvalidOption, pos)
+			}
+			value, err := strconv.Unquote(expr[pos : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: filter: invalid string at %d: %v", ErrInvalidOption, pos, err)
+			}
+			tokens = append(tokens, token{tokenString, value, pos})
+			pos = end + 1
+		case unicode.IsDigit(r) || (r == '-' && pos+1 < len(expr) && unicode.IsDigit(rune(expr[pos+1]))):
+			end := pos + 1
+			for end < len(expr) && (unicode.IsDigit(rune(expr[end])) || expr[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{tokenNumber, expr[pos:end], pos})
+			pos = end
+		case unicode.IsLetter(r) || r == '_':
+			end := pos + 1
+			for end < len(expr) && (unicode.IsLetter(rune(expr[end])) || unicode.IsDigit(rune(expr[end])) || expr[end] == '_') {
+				end++
+			}
+			tokens = append(tokens, token{tokenIdent, expr[pos:end], pos})
+			pos = end
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(expr[pos:], op) {
+					tokens = append(tokens, token{tokenOp, op, pos})
+					pos += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("%w: filter: unexpected character %q at %d", ErrInvalidOption, r, pos)
+			}
+		}
+	}
+	return append(tokens, token{tokenEOF, "end of expression", len(expr)}), nil
+}
+
+// parser is a recursive descent parser of filter expressions
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, a ...interface{}) error {
+	return fmt.Errorf("%w: filter: %s at %d", ErrInvalidOption, fmt.Sprintf(format, a...), tok.pos)
+}
+
+// expect consumes operator op
+func (p *parser) expect(op string) error {
+	if tok := p.next(); tok.kind != tokenOp || tok.text != op {
+		return p.errorf(tok, "expected %q, got %q", op, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (Predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokenOp && tok.text == "||"; tok = p.peek() {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(person *Person) bool { return l(person) || right(person) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Predicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokenOp && tok.text == "&&"; tok = p.peek() {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(person *Person) bool { return l(person) && right(person) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Predicate, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenOp && tok.text == "!":
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(person *Person) bool { return !operand(person) }, nil
+	case tok.kind == tokenOp && tok.text == "(":
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *parser) parseComparison() (Predicate, error) {
+	fieldTok := p.next()
+	if fieldTok.kind != tokenIdent {
+		return nil, p.errorf(fieldTok, "expected field name, got %q", fieldTok.text)
+	}
+	values, err := fieldValues(fieldTok.text)
+	if err != nil {
+		return nil, err
+	}
+
+	opTok := p.next()
+	switch {
+	case opTok.kind == tokenIdent && opTok.text == "in":
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return func(person *Person) bool {
+			for _, value := range values(person) {
+				for _, literal := range list {
+					if compareLiteral(value, literal) == 0 {
+						return true
+					}
+				}
+			}
+			return false
+		}, nil
+	case opTok.kind != tokenOp:
+		return nil, p.errorf(opTok, "expected operator after %q, got %q", fieldTok.text, opTok.text)
+	}
+
+	literal, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+
+	switch opTok.text {
+	case "~", "!~":
+		re, err := regexp.Compile("(?i)" + fmt.Sprint(literal))
+		if err != nil {
+			return nil, p.errorf(opTok, "invalid regular expression: %v", err)
+		}
+		match := func(person *Person) bool {
+			for _, value := range values(person) {
+				if re.MatchString(fmt.Sprint(value)) {
+					return true
+				}
+			}
+			return false
+		}
+		if opTok.text == "!~" {
+			return func(person *Person) bool { return !match(person) }, nil
+		}
+		return match, nil
+	case "!=":
+		return func(person *Person) bool {
+			for _, value := range values(person) {
+				if compareLiteral(value, literal) == 0 {
+					return false
+				}
+			}
+			return true
+		}, nil
+	}
+
+	var accept func(c int) bool
+	switch opTok.text {
+	case "==":
+		accept = func(c int) bool { return c == 0 }
+	case "<":
+		accept = func(c int) bool { return c < 0 }
+	case "<=":
+		accept = func(c int) bool { return c <= 0 }
+	case ">":
+		accept = func(c int) bool { return c > 0 }
+	case ">=":
+		accept = func(c int) bool { return c >= 0 }
+	default:
+		return nil, p.errorf(opTok, "unexpected operator %q", opTok.text)
+	}
+	return func(person *Person) bool {
+		for _, value := range values(person) {
+			if accept(compareLiteral(value, literal)) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// parseList parses list of literals: ["a", "b"]
+func (p *parser) parseList() ([]interface{}, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	var list []interface{}
+	for {
+		literal, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, literal)
+		tok := p.next()
+		if tok.kind == tokenOp && tok.text == "]" {
+			return list, nil
+		}
+		if tok.kind != tokenOp || tok.text != "," {
+			return nil, p.errorf(tok, "expected \",\" or \"]\", got %q", tok.text)
+		}
+	}
+}
+
+// parseLiteral parses string, number or boolean
+func (p *parser) parseLiteral() (interface{}, error) {
+	tok := p.next()
+	switch {
+	case tok.kind == tokenString:
+		return tok.text, nil
+	case tok.kind == tokenNumber:
+		number, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid number %q", tok.text)
+		}
+		return number, nil
+	case tok.kind == tokenIdent && (tok.text == "true" || tok.text == "false"):
+		return tok.text == "true", nil
+	default:
+		return nil, p.errorf(tok, "expected value, got %q", tok.text)
+	}
+}
+
+// compareLiteral compares field value with literal: -1 if value < literal, 1 if value > literal, 0 if equal.
+// Numbers are compared numerically, other values as case-insensitive strings.
+func compareLiteral(value interface{}, literal interface{}) int {
+	if number, ok := literal.(float64); ok {
+		if v, ok := toFloat(value); ok {
+			switch {
+			case v < number:
+				return -1
+			case v > number:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(strings.ToLower(fmt.Sprint(value)), strings.ToLower(fmt.Sprint(literal)))
+}
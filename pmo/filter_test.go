@@ -0,0 +1,137 @@
+package pmo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// filterPeople are engineers used by filter tests
+var filterPeople = []Person{
+	{ID: 1, Name: "Ivan Petrenko", Grade: "SE3", Location: "Kyiv", DaysOnBench: 0,
+		Assignments: []Assignment{{Account: "Acme", Project: "Rocket", Status: "Active"}}},
+	{ID: 2, Name: "Oleksii Kovalenko", Grade: "SE2", Location: "Lviv", DaysOnBench: 15,
+		Assignments: []Assignment{{Account: "Globex", Project: "Core"}, {Account: "Acme", Project: "Web"}}},
+	{ID: 3, Name: "Anna Shevchenko", Grade: "SE4", Location: "Kyiv", DaysOnBench: 40, InBusinessTrip: true},
+}
+
+// selectedIDs returns IDs of engineers matching expr
+func selectedIDs(t *testing.T, expr string) []int {
+	t.Helper()
+	predicate, err := ParseFilter(expr)
+	if err != nil {
+		t.Fatalf("ParseFilter(%q) returned error: %v", expr, err)
+	}
+	ids := []int{}
+	for _, p := range Select(filterPeople, predicate) {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []int
+	}{
+		{`location=="Kyiv"`, []int{1, 3}},
+		{`location=="kyiv"`, []int{1, 3}},
+		{`location!="Kyiv"`, []int{2}},
+		{`daysOnBench>10`, []int{2, 3}},
+		{`daysOnBench>=15`, []int{2, 3}},
+		{`daysOnBench<15`, []int{1}},
+		{`daysOnBench<=15`, []int{1, 2}},
+		{`daysOnBench==-1`, []int{}},
+		{`grade in ["SE3", "se4"]`, []int{1, 3}},
+		{`account~"acm"`, []int{1, 2}},
+		{`account!~"^globex$"`, []int{1, 3}},
+		{`account=="Acme"`, []int{1, 2}},
+		{`account!="Acme"`, []int{3}},
+		{`project in ["Core"]`, []int{2}},
+		{`inBusinessTrip==true`, []int{3}},
+		{`!inBusinessTrip==true`, []int{1, 2}},
+		// && binds tighter than ||
+		{`grade=="SE2" || location=="Kyiv" && daysOnBench>10`, []int{2, 3}},
+		{`(grade=="SE2" || location=="Kyiv") && daysOnBench>10`, []int{2, 3}},
+		{`(grade=="SE3" || location=="Lviv") && daysOnBench>10`, []int{2}},
+		{`grade=="SE3" || location=="Lviv" && daysOnBench>10`, []int{1, 2}},
+		{`!(location=="Kyiv") || grade=="SE4"`, []int{2, 3}},
+		{`!location=="Kyiv" && daysOnBench>10`, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got := selectedIDs(t, tt.expr)
+			if !equalInts(got, tt.want) {
+				t.Errorf("selected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`location="Kyiv"`, "unexpected character '=' at 8"},
+		{`location=="Kyiv`, "unterminated string at 10"},
+		{`location==`, `expected value, got "end of expression" at 10`},
+		{`location "Kyiv"`, `expected operator after "location", got "Kyiv" at 9`},
+		{`=="Kyiv"`, `expected field name, got "==" at 0`},
+		{`(location=="Kyiv"`, `expected ")", got "end of expression" at 17`},
+		{`location=="Kyiv")`, `unexpected ")" at 16`},
+		{`grade in "SE3"`, `expected "[", got "SE3" at 9`},
+		{`grade in ["SE3" "SE4"]`, `expected "," or "]", got "SE4" at 16`},
+		{`account~"("`, "invalid regular expression"},
+		{`location=="Kyiv" && @`, "unexpected character '@' at 20"},
+		{`unknownField==1`, "unknownField"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseFilter(tt.expr)
+			if err == nil {
+				t.Fatalf("ParseFilter(%q) returned no error", tt.expr)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not contain %q", err, tt.want)
+			}
+			if !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("error %v is not ErrInvalidOption", err)
+			}
+		})
+	}
+}
+
+func TestAnd(t *testing.T) {
+	kyiv, _ := ParseFilter(`location=="Kyiv"`)
+	bench, _ := ParseFilter(`daysOnBench>10`)
+	tests := []struct {
+		name       string
+		predicates []Predicate
+		want       int
+	}{
+		{"no predicates", nil, 3},
+		{"nil skipped", []Predicate{nil, kyiv}, 2},
+		{"both", []Predicate{kyiv, bench}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(Select(filterPeople, And(tt.predicates...))); got != tt.want {
+				t.Errorf("selected %d engineers, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// equalInts reports whether slices have the same elements in the same order
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
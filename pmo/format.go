@@ -0,0 +1,98 @@
+package pmo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported configuration file formats
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// configExtensions lists config file extensions in lookup order
+var configExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// ConfigFormat returns format of configuration file by its extension
+func ConfigFormat(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".toml":
+		return FormatTOML, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported config file extension %q, use .json, .yaml, .yml or .toml", ErrConfig, ext)
+	}
+}
+
+// configToJSON converts configuration in format to JSON. Field names are the same in all formats,
+// so every format is decoded and validated by JSON rules.
+func configToJSON(raw []byte, format string) ([]byte, error) {
+	var document map[string]interface{}
+	switch format {
+	case FormatJSON:
+		return raw, nil
+	case FormatYAML:
+		if err := yaml.Unmarshal(raw, &document); err != nil {
+			return nil, fmt.Errorf("%w: can not parse YAML: %v", ErrConfig, err)
+		}
+	case FormatTOML:
+		if err := toml.Unmarshal(raw, &document); err != nil {
+			return nil, fmt.Errorf("%w: can not parse TOML: %v", ErrConfig, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported config format %q", ErrConfig, format)
+	}
+
+	if document == nil {
+		document = map[string]interface{}{}
+	}
+	converted, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("%w: can not convert %v config: %v", ErrConfig, format, err)
+	}
+	return converted, nil
+}
+
+// ConvertConfig translates configuration between formats. Comments are not preserved
+// and keys are sorted alphabetically.
+func ConvertConfig(raw []byte, from string, to string) ([]byte, error) {
+	converted, err := configToJSON(raw, from)
+	if err != nil {
+		return nil, err
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(converted, &document); err != nil {
+		return nil, fmt.Errorf("%w: can not decode config: %v", ErrConfig, err)
+	}
+
+	var buf bytes.Buffer
+	switch to {
+	case FormatJSON:
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "    ")
+		err = encoder.Encode(document)
+	case FormatYAML:
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		err = encoder.Encode(document)
+	case FormatTOML:
+		err = toml.NewEncoder(&buf).Encode(document)
+	default:
+		return nil, fmt.Errorf("%w: unsupported config format %q", ErrConfig, to)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: can not encode config as %v: %v", ErrConfig, to, err)
+	}
+	return buf.Bytes(), nil
+}
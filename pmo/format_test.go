@@ -0,0 +1,128 @@
+package pmo
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigFormat(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"pmoclient.json", FormatJSON, false},
+		{"/etc/pmoclient.YAML", FormatYAML, false},
+		{"pmoclient.yml", FormatYAML, false},
+		{"pmoclient.toml", FormatTOML, false},
+		{"pmoclient.ini", "", true},
+		{"pmoclient", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ConfigFormat(tt.path)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ConfigFormat(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+			}
+			if err != nil && !errors.Is(err, ErrConfig) {
+				t.Errorf("ConfigFormat(%q) error %v is not ErrConfig", tt.path, err)
+			}
+		})
+	}
+}
+
+// formatConfigs is the same configuration in every supported format
+var formatConfigs = map[string]string{
+	FormatJSON: `{"username": "u", "filterUsers": ["Ivan Petrenko", "Anna Shevchenko"],
+		"profiles": {"prod": {"loginUrl": "https://pmo/login"}}}`,
+	FormatYAML: `
+username: u
+filterUsers:
+  - Ivan Petrenko
+  - Anna Shevchenko
+profiles:
+  prod:
+    loginUrl: https://pmo/login
+`,
+	FormatTOML: `
+username = "u"
+filterUsers = ["Ivan Petrenko", "Anna Shevchenko"]
+
+[profiles.prod]
+loginUrl = "https://pmo/login"
+`,
+}
+
+func TestLoadConfigFormats(t *testing.T) {
+	want := Configuration{Username: "u", FilterUsers: []string{"Ivan Petrenko", "Anna Shevchenko"},
+		LoginURL: "https://pmo/login", Name: "prod"}
+	for format, content := range formatConfigs {
+		t.Run(format, func(t *testing.T) {
+			name := "pmoclient." + format
+			dir, remove := configDir(t, map[string]string{name: content})
+			defer remove()
+			defer setEnv(t, nil)()
+
+			config, _, err := LoadConfig(filepath.Join(dir, name), "prod")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(config, want) {
+				t.Errorf("config = %+v, want %+v", config, want)
+			}
+		})
+	}
+}
+
+func TestConvertConfig(t *testing.T) {
+	formats := []string{FormatJSON, FormatYAML, FormatTOML}
+	for _, from := range formats {
+		for _, to := range formats {
+			t.Run(from+" to "+to, func(t *testing.T) {
+				converted, err := ConvertConfig([]byte(formatConfigs[from]), from, to)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := configToJSON(converted, to)
+				if err != nil {
+					t.Fatalf("converted config can not be read: %v\n%s", err, converted)
+				}
+				want, _ := configToJSON([]byte(formatConfigs[from]), from)
+				var gotFile, wantFile Configuration
+				if err := json.Unmarshal(got, &gotFile); err != nil {
+					t.Fatal(err)
+				}
+				if err := json.Unmarshal(want, &wantFile); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(gotFile, wantFile) {
+					t.Errorf("converted config is %+v, want %+v", gotFile, wantFile)
+				}
+			})
+		}
+	}
+}
+
+func TestConvertConfigErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		from, to string
+	}{
+		{"invalid YAML", "username: [", FormatYAML, FormatJSON},
+		{"invalid TOML", "username = ", FormatTOML, FormatJSON},
+		{"invalid JSON", "{", FormatJSON, FormatYAML},
+		{"unknown source format", "{}", "ini", FormatJSON},
+		{"unknown target format", "{}", FormatJSON, "ini"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ConvertConfig([]byte(tt.raw), tt.from, tt.to); !errors.Is(err, ErrConfig) {
+				t.Errorf("ConvertConfig returned %v, want ErrConfig", err)
+			}
+		})
+	}
+}
@@ -1,39 +1,12 @@
 package pmo
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"os"
-	"os/user"
-	"sort"
+	"io"
 	"strings"
 	"text/tabwriter"
 )
 
-// ReadConfig gets information from config file and creates structure `Configuration`
-func ReadConfig(relativeConfigFilePath string) Configuration {
-	usr, err := user.Current()
-	if err != nil {
-		log.Fatal(err)
-	}
-	configFileName := usr.HomeDir + relativeConfigFilePath
-	raw, err := ioutil.ReadFile(configFileName) // nolint: gosec
-	if err != nil {
-		log.Fatalf("error %q happened reading config file %v. raw: %v", err, configFileName, raw)
-	}
-
-	config := Configuration{}
-	err = json.Unmarshal(raw, &config)
-	if err != nil {
-		log.Fatalf("something happened during unmarshall config: %q. engineers: %v", err, config)
-	}
-
-	return config
-
-}
-
 // RemoveDuplicates helper function to remove duplicates
 func RemoveDuplicates(elements []string) []string {
 	// Use map to record duplicates as we find them.
@@ -54,43 +27,76 @@ func RemoveDuplicates(elements []string) []string {
 	return result
 }
 
-// PrintTable prints table representation of engineers
-func PrintTable(engineers []Person, formatString string) {
-	// Observe how the b's and the d's, despite appearing in the
-	// second cell of each line, belong to different columns.
-	//w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
-	w := tabwriter.NewWriter(os.Stdout, 5, 0, 1, ' ', 0)
+// PrintTable prints table representation of engineers to w with columns
+func PrintTable(w io.Writer, engineers []Person, columns []Column) error {
+	tw := tabwriter.NewWriter(w, 5, 0, 1, ' ', 0)
 	// print header
-	_, err := fmt.Fprintf(w, formatString,
-		"Name",
-		"Grade",
-		"Profile",
-		"Account",
-		"Project",
-		"Manager",
-		"Status")
-	if err != nil {
-		log.Fatalf("[ERR] railed to print formatString %q. E: %v", formatString, err)
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, column.Header)
 	}
-	// iterate over engineers and print only required from config
-	filtered := ByLocation(engineers)
-	sort.Sort(filtered) // sort by location
-	for _, engineer := range filtered {
-		_, err := fmt.Fprintf(w, formatString,
-			engineer.Name,
-			engineer.Grade,
-			engineer.Profile,
-			engineer.GetAccountsString(),
-			engineer.GetProjectsString(),
-			engineer.Manager,
-			strings.Join(RemoveDuplicates(engineer.AssignmentStatuses()), ","))
-		if err != nil {
-			log.Fatalf("[ERR] failed on writing %v to tabwriter. E: %v", engineer, err)
+	if _, err := fmt.Fprintln(tw, strings.Join(header, "\t")); err != nil {
+		return fmt.Errorf("%w: failed to print header: %v", ErrOutput, err)
+	}
+
+	for i := range engineers {
+		row := make([]string, 0, len(columns))
+		for _, column := range columns {
+			row = append(row, fmt.Sprint(column.Value(&engineers[i])))
+		}
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return fmt.Errorf("%w: failed on writing %v to tabwriter: %v", ErrOutput, engineers[i].Name, err)
 		}
 	}
 
-	if err := w.Flush(); err != nil {
-		log.Fatalln("can not flush tabwriter")
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("%w: can not flush tabwriter: %v", ErrOutput, err)
+	}
+	return nil
+}
+
+// PrintPerson prints all information about engineer including assignments
+func PrintPerson(w io.Writer, engineer Person) error {
+	tw := tabwriter.NewWriter(w, 5, 0, 1, ' ', 0)
+	fields := []struct {
+		name  string
+		value interface{}
+	}{
+		{"Name", engineer.Name},
+		{"ID", engineer.ID},
+		{"Username", engineer.Username},
+		{"Grade", engineer.Grade},
+		{"Specialization", engineer.Specialization},
+		{"Profile", engineer.Profile},
+		{"Position", engineer.Position},
+		{"ServiceLine", engineer.ServiceLine},
+		{"Location", engineer.Location},
+		{"Manager", engineer.Manager},
+		{"EngineeringManagers", strings.Join(engineer.GetEngineerManagers(), ",")},
+		{"AvailableDays", engineer.AvailableDays},
+		{"DaysOnBench", engineer.DaysOnBench},
+		{"InBusinessTrip", engineer.InBusinessTrip},
+		{"Source", engineer.Source},
+	}
+	for _, field := range fields {
+		if _, err := fmt.Fprintf(tw, "%s\t%v\n", field.name, field.value); err != nil {
+			return fmt.Errorf("%w: failed to print %v: %v", ErrOutput, field.name, err)
+		}
 	}
 
+	if _, err := fmt.Fprintf(tw, "\nID\tAccount\tProject\tStart\tFinish\tInvolvement\tStatus\tComment\n"); err != nil {
+		return fmt.Errorf("%w: failed to print assignments header: %v", ErrOutput, err)
+	}
+	for _, a := range engineer.Assignments {
+		_, err := fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%g\t%s\t%s\n",
+			a.ID, a.Account, a.Project, FormatDate(a.Start), FormatDate(a.Finish), a.Involvement, a.Status, a.Comment)
+		if err != nil {
+			return fmt.Errorf("%w: failed to print assignment %d: %v", ErrOutput, a.ID, err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("%w: can not flush tabwriter: %v", ErrOutput, err)
+	}
+	return nil
 }
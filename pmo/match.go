@@ -0,0 +1,249 @@
+package pmo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxSuggestions is number of names suggested for unmatched filter entry
+const maxSuggestions = 3
+
+// Unmatched is filter entry which does not match exactly one engineer
+type Unmatched struct {
+	Name string `json:"name"`
+	// Ambiguous is set if entry matches several engineers. Suggestions are matched engineers then.
+	Ambiguous   bool     `json:"ambiguous,omitempty"`
+	Suggestions []string `json:"suggestions"`
+}
+
+func (u Unmatched) String() string {
+	switch {
+	case u.Ambiguous:
+		return fmt.Sprintf("%q is ambiguous, use username or ID of one of: %s", u.Name, strings.Join(u.Suggestions, ", "))
+	case len(u.Suggestions) == 0:
+		return fmt.Sprintf("%q not found in PMO", u.Name)
+	default:
+		return fmt.Sprintf("%q not found in PMO, did you mean: %s?", u.Name, strings.Join(u.Suggestions, ", "))
+	}
+}
+
+// Matcher resolves filter entries to engineers. Entry may be an alias, engineer ID, username or name.
+// Names are compared ignoring case, diacritics, punctuation and order of words; entry without
+// middle name matches full name. Entry written without spaces, like `JohnSmith`, matches name with words in the same order.
+type Matcher struct {
+	engineers []Person
+	aliases   map[string]string
+}
+
+// NewMatcher returns matcher for engineers. Aliases map local names to PMO ID, username or name.
+func NewMatcher(engineers []Person, aliases map[string]string) *Matcher {
+	m := &Matcher{engineers: engineers, aliases: make(map[string]string)}
+	for alias, target := range aliases {
+		m.aliases[nameKey(alias)] = target
+	}
+	return m
+}
+
+// Find returns all engineers matching entry
+func (m *Matcher) Find(entry string) []Person {
+	var found []Person
+	for _, i := range m.find(entry) {
+		found = append(found, m.engineers[i])
+	}
+	return found
+}
+
+// find returns indexes of engineers matching entry. The first rule matching anybody wins.
+func (m *Matcher) find(entry string) []int {
+	entry = strings.TrimSpace(entry)
+	if target, ok := m.aliases[nameKey(entry)]; ok {
+		entry = strings.TrimSpace(target)
+	}
+	if id, err := strconv.Atoi(entry); err == nil {
+		return m.indexes(func(p *Person) bool { return p.ID == id })
+	}
+
+	if found := m.indexes(func(p *Person) bool { return p.Username != "" && strings.EqualFold(p.Username, entry) }); len(found) > 0 {
+		return found
+	}
+
+	key := nameKey(entry)
+	if found := m.indexes(func(p *Person) bool { return nameKey(p.Name) == key }); len(found) > 0 {
+		return found
+	}
+
+	compact := compactKey(entry)
+	if found := m.indexes(func(p *Person) bool { return compactKey(p.Name) == compact }); len(found) > 0 {
+		return found
+	}
+
+	words := nameWords(entry)
+	if len(words) < 2 {
+		return nil
+	}
+	return m.indexes(func(p *Person) bool { return containsWords(nameWords(p.Name), words) })
+}
+
+// indexes returns indexes of engineers matching predicate
+func (m *Matcher) indexes(predicate Predicate) []int {
+	var result []int
+	for i := range m.engineers {
+		if predicate(&m.engineers[i]) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// Match returns engineers matching entries in original order and entries which match nobody or several engineers.
+func (m *Matcher) Match(entries []string) ([]Person, []Unmatched) {
+	selected := make(map[int]bool)
+	var unmatched []Unmatched
+	for _, entry := range RemoveDuplicates(entries) {
+		found := m.find(entry)
+		switch len(found) {
+		case 0:
+			unmatched = append(unmatched, Unmatched{Name: entry, Suggestions: Suggest(m.engineers, entry)})
+		case 1:
+			selected[found[0]] = true
+		default:
+			u := Unmatched{Name: entry, Ambiguous: true}
+			for _, i := range found {
+				u.Suggestions = append(u.Suggestions, identity(m.engineers[i]))
+			}
+			unmatched = append(unmatched, u)
+		}
+	}
+
+	var matched []Person
+	for i, engineer := range m.engineers {
+		if selected[i] {
+			matched = append(matched, engineer)
+		}
+	}
+	return matched, unmatched
+}
+
+// identity describes engineer with name, username and ID
+func identity(p Person) string {
+	if p.Username == "" {
+		return fmt.Sprintf("%s (ID %d)", p.Name, p.ID)
+	}
+	return fmt.Sprintf("%s (%s, ID %d)", p.Name, p.Username, p.ID)
+}
+
+// Suggest returns names of engineers nearest to name by edit distance, nearest first.
+// Names too far from the original are not suggested.
+func Suggest(engineers []Person, name string) []string {
+	key := []rune(compactKey(name))
+	limit := len(key) / 3
+	if limit < 2 {
+		limit = 2
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	seen := make(map[string]bool)
+	for _, engineer := range engineers {
+		if seen[engineer.Name] {
+			continue
+		}
+		seen[engineer.Name] = true
+		if d := editDistance(key, []rune(compactKey(engineer.Name))); d <= limit {
+			candidates = append(candidates, candidate{engineer.Name, d})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var result []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		result = append(result, candidates[i].name)
+	}
+	return result
+}
+
+// nameWords splits name to lowercase words without diacritics and punctuation
+func nameWords(name string) []string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Fields(b.String())
+}
+
+// nameKey normalizes name for matching: words of name in alphabetical order
+func nameKey(name string) string {
+	words := nameWords(name)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// compactKey normalizes name for space-insensitive matching: words of name joined without spaces
+func compactKey(name string) string {
+	return strings.Join(nameWords(name), "")
+}
+
+// containsWords reports whether every word of sub is in words, respecting repetitions
+func containsWords(words, sub []string) bool {
+	count := make(map[string]int)
+	for _, w := range words {
+		count[w]++
+	}
+	for _, w := range sub {
+		if count[w] == 0 {
+			return false
+		}
+		count[w]--
+	}
+	return true
+}
+
+// editDistance is Levenshtein distance between a and b
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
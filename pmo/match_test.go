@@ -0,0 +1,149 @@
+package pmo
+
+import (
+	"reflect"
+	"testing"
+)
+
+// matchPeople are engineers used by matcher tests
+var matchPeople = []Person{
+	{ID: 1, Name: "Ivan Petrenko", Username: "ipetrenko"},
+	{ID: 2, Name: "Oleksii Kovalenko", Username: "okovalenko"},
+	{ID: 3, Name: "Anna Shevchenko", Username: "ashevchenko"},
+	{ID: 4, Name: "Anna Shevchenko", Username: "ashevchenko2"},
+	{ID: 5, Name: "Mykola Ivanovych Bondar", Username: "mbondar"},
+	{ID: 6, Name: "Zoë Müller-Lęcka", Username: "zmuller"},
+}
+
+func TestMatcherFind(t *testing.T) {
+	aliases := map[string]string{
+		"Alexey Kovalenko": "okovalenko",
+		"Anna S.":          "4",
+		"Boss":             " 1 ",
+	}
+	tests := []struct {
+		entry string
+		want  []int
+	}{
+		{"1", []int{1}},
+		{" 2 ", []int{2}},
+		{"42", nil},
+		{"ipetrenko", []int{1}},
+		{"IPetrenko", []int{1}},
+		{"Ivan Petrenko", []int{1}},
+		{"ivan  petrenko", []int{1}},
+		{"Petrenko Ivan", []int{1}},
+		{"Petrenko, Ivan", []int{1}},
+		{"IvanPetrenko", []int{1}},
+		{"ivanpetrenko", []int{1}},
+		{"PetrenkoIvan", nil},
+		{"Zoe Muller Lecka", []int{6}},
+		{"Zoë Müller-Lęcka", []int{6}},
+		{"Mykola Bondar", []int{5}},
+		{"Bondar", nil},
+		{"Anna Shevchenko", []int{3, 4}},
+		{"ashevchenko2", []int{4}},
+		{"Alexey Kovalenko", []int{2}},
+		{"alexey  kovalenko", []int{2}},
+		{"Anna S.", []int{4}},
+		{"Boss", []int{1}},
+		{"Ivan Petrenko Jr", nil},
+	}
+	m := NewMatcher(matchPeople, aliases)
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			var got []int
+			for _, p := range m.Find(tt.entry) {
+				got = append(got, p.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find(%q) = %v, want %v", tt.entry, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherMatch(t *testing.T) {
+	m := NewMatcher(matchPeople, nil)
+	matched, unmatched := m.Match([]string{"okovalenko", "Ivan Petrenko", "Anna Shevchenko", "Ana Shevcenko",
+		"Nobody Here", "ipetrenko", "Ivan Petrenko"})
+
+	var ids []int
+	for _, p := range matched {
+		ids = append(ids, p.ID)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("matched %v, want %v in order of engineers", ids, want)
+	}
+
+	want := []Unmatched{
+		{Name: "Anna Shevchenko", Ambiguous: true,
+			Suggestions: []string{"Anna Shevchenko (ashevchenko, ID 3)", "Anna Shevchenko (ashevchenko2, ID 4)"}},
+		{Name: "Ana Shevcenko", Suggestions: []string{"Anna Shevchenko"}},
+		{Name: "Nobody Here"},
+	}
+	if !reflect.DeepEqual(unmatched, want) {
+		t.Errorf("unmatched %+v, want %+v", unmatched, want)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"Ivan Petrenk", []string{"Ivan Petrenko"}},
+		{"Olexii Kovalenko", []string{"Oleksii Kovalenko"}},
+		{"Ana Shevcenko", []string{"Anna Shevchenko"}},
+		{"Ivan", nil},
+		{"Completely Different", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Suggest(matchPeople, tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"anna", "ana", 1},
+		{"shevchenko", "shevcenko", 1},
+		{"ab", "ba", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+				t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameWords(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"Ivan Petrenko", []string{"ivan", "petrenko"}},
+		{"  Petrenko,  Ivan ", []string{"petrenko", "ivan"}},
+		{"Zoë Müller-Lęcka", []string{"zoe", "muller", "lecka"}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nameWords(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nameWords(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+package pmo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats supported by WriteEngineers
+const (
+	OutputTable    = "table"
+	OutputJSON     = "json"
+	OutputNDJSON   = "ndjson"
+	OutputCSV      = "csv"
+	OutputTSV      = "tsv"
+	OutputYAML     = "yaml"
+	OutputMarkdown = "markdown"
+)
+
+// OutputFormats lists supported output formats
+var OutputFormats = []string{OutputTable, OutputJSON, OutputNDJSON, OutputCSV, OutputTSV, OutputYAML, OutputMarkdown}
+
+// OutputOptions configure WriteEngineers
+type OutputOptions struct {
+	// Format is one of OutputFormats. Empty means table.
+	Format string
+	// Columns of table, csv, tsv and markdown output. Empty means DefaultColumns for table
+	// and every field for other formats.
+	Columns []Column
+	// Sort keys. Empty means DefaultSort.
+	Sort []SortKey
+	// Template is executed against engineers instead of Format if set
+	Template *template.Template
+}
+
+// flatColumns are columns with every Person field except nested ones
+var flatColumns = []string{"id", "name", "username", "grade", "specialization", "profile", "position",
+	"serviceLine", "location", "manager", "availableDays", "daysOnBench", "inBusinessTrip", "source"}
+
+// WriteEngineers writes sorted engineers to w
+func WriteEngineers(w io.Writer, engineers []Person, opts OutputOptions) error {
+	sorted := make([]Person, len(engineers))
+	copy(sorted, engineers)
+	keys := opts.Sort
+	if len(keys) == 0 {
+		keys = DefaultSort
+	}
+	SortEngineers(sorted, keys)
+
+	if opts.Template != nil {
+		return WriteTemplate(w, sorted, opts.Template)
+	}
+
+	columns := opts.Columns
+	switch opts.Format {
+	case OutputTable, "":
+		if len(columns) == 0 {
+			columns = withSource(sorted, columnsOrPanic(DefaultColumns))
+		}
+		return PrintTable(w, sorted, columns)
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return wrapOutput(encoder.Encode(nonNil(sorted)))
+	case OutputNDJSON:
+		encoder := json.NewEncoder(w)
+		for _, engineer := range sorted {
+			if err := encoder.Encode(engineer); err != nil {
+				return wrapOutput(err)
+			}
+		}
+		return nil
+	case OutputYAML:
+		return writeYAML(w, nonNil(sorted))
+	case OutputCSV:
+		return writeCSV(w, sorted, columns, ',')
+	case OutputTSV:
+		return writeCSV(w, sorted, columns, '\t')
+	case OutputMarkdown:
+		return writeMarkdown(w, sorted, columns)
+	default:
+		return fmt.Errorf("%w: unknown output format %q, use one of %s",
+			ErrOutput, opts.Format, strings.Join(OutputFormats, ", "))
+	}
+}
+
+// Table is report prepared for table, CSV, TSV and markdown output and for spreadsheet.
+// Cells are strings or numbers.
+type Table struct {
+	// Title is printed before table if report has several tables
+	Title  string
+	Header []string
+	Rows   [][]interface{}
+}
+
+// WriteReport writes report to w. JSON, NDJSON and YAML formats encode records: every element of slice
+// is separate NDJSON line. Table, CSV, TSV and markdown formats write tables separated by empty line.
+func WriteReport(w io.Writer, format string, records interface{}, tables ...Table) error {
+	switch format {
+	case OutputTable, "":
+		return writeTables(w, tables, "%s\n", printTable)
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return wrapOutput(encoder.Encode(nonNilSlice(records)))
+	case OutputNDJSON:
+		encoder := json.NewEncoder(w)
+		list := reflect.ValueOf(records)
+		if list.Kind() != reflect.Slice {
+			return wrapOutput(encoder.Encode(records))
+		}
+		for i := 0; i < list.Len(); i++ {
+			if err := encoder.Encode(list.Index(i).Interface()); err != nil {
+				return wrapOutput(err)
+			}
+		}
+		return nil
+	case OutputYAML:
+		return writeYAML(w, nonNilSlice(records))
+	case OutputCSV:
+		return writeTables(w, tables, "", func(w io.Writer, table Table) error { return writeTableCSV(w, table, ',') })
+	case OutputTSV:
+		return writeTables(w, tables, "", func(w io.Writer, table Table) error { return writeTableCSV(w, table, '\t') })
+	case OutputMarkdown:
+		return writeTables(w, tables, "### %s\n\n", writeTableMarkdown)
+	default:
+		return fmt.Errorf("%w: unknown output format %q, use one of %s",
+			ErrOutput, format, strings.Join(OutputFormats, ", "))
+	}
+}
+
+// Strings returns header and rows of table as strings
+func (t Table) Strings() [][]string {
+	rows := make([][]string, 0, len(t.Rows)+1)
+	rows = append(rows, t.Header)
+	for _, row := range t.Rows {
+		cells := make([]string, 0, len(row))
+		for _, cell := range row {
+			cells = append(cells, fmt.Sprint(cell))
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+// addSourceColumn prepends Source column with sources of rows if any of them is set,
+// i.e. if report is merged from several profiles. sources are in order of rows.
+func (t *Table) addSourceColumn(sources []string) {
+	merged := false
+	for _, source := range sources {
+		merged = merged || source != ""
+	}
+	if !merged {
+		return
+	}
+	t.Header = append([]string{"Source"}, t.Header...)
+	for i := range t.Rows {
+		t.Rows[i] = append([]interface{}{sources[i]}, t.Rows[i]...)
+	}
+}
+
+// writeTables writes tables with write function separated by empty line. If report has several tables,
+// titles are written with titleFormat unless it is empty.
+func writeTables(w io.Writer, tables []Table, titleFormat string, write func(io.Writer, Table) error) error {
+	for i, table := range tables {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return wrapOutput(err)
+			}
+		}
+		if len(tables) > 1 && table.Title != "" && titleFormat != "" {
+			if _, err := fmt.Fprintf(w, titleFormat, table.Title); err != nil {
+				return wrapOutput(err)
+			}
+		}
+		if err := write(w, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printTable prints table aligned with tabwriter
+func printTable(w io.Writer, table Table) error {
+	tw := tabwriter.NewWriter(w, 5, 0, 1, ' ', 0)
+	for _, row := range table.Strings() {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return wrapOutput(err)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("%w: can not flush tabwriter: %v", ErrOutput, err)
+	}
+	return nil
+}
+
+// writeTableCSV writes table as CSV with delimiter
+func writeTableCSV(w io.Writer, table Table, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+	if err := writer.WriteAll(table.Strings()); err != nil {
+		return wrapOutput(err)
+	}
+	return nil
+}
+
+// writeTableMarkdown writes table as markdown table
+func writeTableMarkdown(w io.Writer, table Table) error {
+	rows := table.Strings()
+	separator := make([]string, len(table.Header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	rows = append([][]string{rows[0], separator}, rows[1:]...)
+	for _, row := range rows {
+		for i := range row {
+			row[i] = markdownEscape(row[i])
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+			return wrapOutput(err)
+		}
+	}
+	return nil
+}
+
+// nonNilSlice returns empty slice instead of nil slice to encode it as empty list
+func nonNilSlice(records interface{}) interface{} {
+	list := reflect.ValueOf(records)
+	if list.Kind() == reflect.Slice && list.IsNil() {
+		return reflect.MakeSlice(list.Type(), 0, 0).Interface()
+	}
+	return records
+}
+
+// withSource adds source column if engineers are merged from several profiles
+func withSource(engineers []Person, columns []Column) []Column {
+	for _, engineer := range engineers {
+		if engineer.Source != "" {
+			return append([]Column{mustColumn("source")}, columns...)
+		}
+	}
+	return columns
+}
+
+// columnsOrPanic returns columns with predefined names
+func columnsOrPanic(names []string) []Column {
+	columns, err := columnsByName(names)
+	if err != nil {
+		panic(err)
+	}
+	return columns
+}
+
+// nonNil returns empty slice instead of nil to encode it as empty list
+func nonNil(engineers []Person) []Person {
+	if engineers == nil {
+		return []Person{}
+	}
+	return engineers
+}
+
+// wrapOutput wraps error with ErrOutput
+func wrapOutput(err error) error {
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrOutput, err)
+	}
+	return nil
+}
+
+// writeYAML writes v as YAML with the same field names as JSON output
+func writeYAML(w io.Writer, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return wrapOutput(err)
+	}
+	var document interface{}
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return wrapOutput(err)
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return wrapOutput(err)
+	}
+	return wrapOutput(encoder.Close())
+}
+
+// writeCSV writes engineers as CSV with delimiter. Without columns every field is written:
+// assignments and engineering managers are encoded as JSON arrays.
+func writeCSV(w io.Writer, engineers []Person, columns []Column, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	nested := len(columns) == 0
+	if nested {
+		columns = columnsOrPanic(flatColumns)
+	}
+	header := make([]string, 0, len(columns)+2)
+	for _, column := range columns {
+		header = append(header, column.Name)
+	}
+	if nested {
+		header = append(header, "engineerManagers", "assignments")
+	}
+	if err := writer.Write(header); err != nil {
+		return wrapOutput(err)
+	}
+
+	for i := range engineers {
+		engineer := &engineers[i]
+		record := make([]string, 0, len(header))
+		for _, column := range columns {
+			record = append(record, fmt.Sprint(column.Value(engineer)))
+		}
+		if nested {
+			managers, err := json.Marshal(engineer.EngineerManagers)
+			if err != nil {
+				return wrapOutput(err)
+			}
+			assignments, err := json.Marshal(engineer.Assignments)
+			if err != nil {
+				return wrapOutput(err)
+			}
+			record = append(record, string(managers), string(assignments))
+		}
+		if err := writer.Write(record); err != nil {
+			return wrapOutput(err)
+		}
+	}
+	writer.Flush()
+	return wrapOutput(writer.Error())
+}
+
+// writeMarkdown writes engineers as markdown table. Without columns every field is written:
+// nested fields are listed in the cell.
+func writeMarkdown(w io.Writer, engineers []Person, columns []Column) error {
+	nested := len(columns) == 0
+	if nested {
+		columns = columnsOrPanic(flatColumns)
+	}
+	header := make([]string, 0, len(columns)+2)
+	for _, column := range columns {
+		header = append(header, column.Name)
+	}
+	if nested {
+		header = append(header, "engineerManagers", "assignments")
+	}
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+
+	rows := [][]string{header, separator}
+	for i := range engineers {
+		engineer := &engineers[i]
+		row := make([]string, 0, len(header))
+		for _, column := range columns {
+			row = append(row, markdownEscape(fmt.Sprint(column.Value(engineer))))
+		}
+		if nested {
+			assignments := make([]string, 0, len(engineer.Assignments))
+			for _, a := range engineer.Assignments {
+				assignments = append(assignments, markdownEscape(
+					fmt.Sprintf("%s/%s %g%% %s (%s..%s)", a.Account, a.Project, a.Involvement, a.Status,
+						FormatDate(a.Start), FormatDate(a.Finish))))
+			}
+			row = append(row,
+				markdownEscape(strings.Join(engineer.GetEngineerManagers(), ", ")),
+				strings.Join(assignments, "<br>"))
+		}
+		rows = append(rows, row)
+	}
+
+	for _, row := range rows {
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+			return wrapOutput(err)
+		}
+	}
+	return nil
+}
+
+// markdownEscape escapes characters breaking markdown table cell
+func markdownEscape(s string) string {
+	s = strings.Replace(s, "|", "\\|", -1)
+	return strings.Replace(s, "\n", " ", -1)
+}
@@ -1,8 +1,8 @@
 package pmo
 
 import (
-	"fmt"
 	"strings"
+	"time"
 )
 
 // employee data
@@ -19,21 +19,6 @@ type engineerManagers struct {
 	Discipline string   `json:"discipline"`
 }
 
-// assignment defines engineer assignment
-type assignment struct {
-	ID          int    `json:"id"`
-	EmployeeID  int    `json:"employeeId"`
-	Account     string `json:"account"`
-	Project     string `json:"project"`
-	Start       string `json:"start"`
-	Finish      string `json:"finish"`
-	StartDate   string `json:"startDate"`
-	FinishDate  string `json:"finishDate"`
-	Involvement int    `json:"involvement"`
-	Status      string `json:"status"`
-	Comment     string `json:"comment"`
-}
-
 // Person contains person-related information presented in PMO
 type Person struct {
 	ID               int                `json:"id"`
@@ -48,25 +33,26 @@ type Person struct {
 	Manager          string             `json:"manager"`
 	AvailableDays    int                `json:"availableDays"`
 	DaysOnBench      int                `json:"daysOnBench"`
-	Assignments      []assignment       `json:"assignments"`
+	Assignments      []Assignment       `json:"assignments"`
 	EngineerManagers []engineerManagers `json:"engineerManagers"`
 	InBusinessTrip   bool               `json:"inBusinessTrip"`
+	// Source is a name of config profile person was fetched from. Set when several profiles are merged.
+	Source string `json:"source,omitempty"`
 }
 
 // GetAssignmentsString returns assignments in form `account-project-involvement`
 func (p *Person) GetAssignmentsString() []string {
-	assignments := make([]string, len(p.Assignments))
+	assignments := make([]string, 0, len(p.Assignments))
 
 	for _, assignment := range p.Assignments {
-		assignments = append(assignments,
-			fmt.Sprintf("%q-%q-%d", assignment.Account, assignment.Project, assignment.Involvement))
+		assignments = append(assignments, assignment.String())
 	}
 	return assignments
 }
 
 // GetEngineerManagers return list of managers
 func (p *Person) GetEngineerManagers() []string {
-	managers := make([]string, len(p.EngineerManagers))
+	managers := make([]string, 0, len(p.EngineerManagers))
 
 	for _, manager := range p.EngineerManagers {
 		managers = append(managers, manager.Employee.Username)
@@ -76,7 +62,7 @@ func (p *Person) GetEngineerManagers() []string {
 
 // GetAccounts returns list of accounts this engineer is working on
 func (p *Person) GetAccounts() []string {
-	accounts := make([]string, len(p.Assignments))
+	accounts := make([]string, 0, len(p.Assignments))
 
 	for _, assignment := range p.Assignments {
 		accounts = append(accounts, assignment.Account)
@@ -86,7 +72,7 @@ func (p *Person) GetAccounts() []string {
 
 // GetProjects returns list of projects this engineer is assigned to
 func (p *Person) GetProjects() []string {
-	projects := make([]string, len(p.Assignments))
+	projects := make([]string, 0, len(p.Assignments))
 
 	for _, assignment := range p.Assignments {
 		projects = append(projects, assignment.Project)
@@ -96,13 +82,40 @@ func (p *Person) GetProjects() []string {
 
 // AssignmentStatuses returns list of assignment statuses
 func (p *Person) AssignmentStatuses() []string {
-	result := make([]string, len(p.Assignments))
+	result := make([]string, 0, len(p.Assignments))
 	for _, element := range p.Assignments {
 		result = append(result, element.Status)
 	}
 	return result
 }
 
+// TotalInvolvement returns sum of involvement in all assignments in percent
+func (p *Person) TotalInvolvement() float64 {
+	total := 0.0
+	for _, assignment := range p.Assignments {
+		total += assignment.Involvement
+	}
+	return total
+}
+
+// ActiveAssignments returns assignments active on the day of date
+func (p *Person) ActiveAssignments(date time.Time) []Assignment {
+	active := make([]Assignment, 0, len(p.Assignments))
+	for _, assignment := range p.Assignments {
+		if assignment.ActiveOn(date) {
+			active = append(active, assignment)
+		}
+	}
+	return active
+}
+
+// AsOf returns copy of person with assignments active on the day of date only,
+// so accounts, projects and statuses are computed for that day
+func (p Person) AsOf(date time.Time) Person {
+	p.Assignments = p.ActiveAssignments(date)
+	return p
+}
+
 // GetAccountsString return list of accounts as a string
 func (p *Person) GetAccountsString() string {
 	return strings.Join(p.GetAccounts(), ",")
@@ -115,6 +128,8 @@ func (p *Person) GetProjectsString() string {
 
 // ByLocation implements sort.Interface for []Person based on
 // the Location field.
+//
+// Deprecated: use SortEngineers with `location` sort key.
 type ByLocation []Person
 
 func (slice ByLocation) Len() int {
@@ -137,6 +152,6 @@ type APIResponse struct {
 
 // EngineersSpreadsheet is google spreadsheet with engineering data
 type EngineersSpreadsheet struct {
-	SpreadsheetID string `json:"SpreadsheetID"`
-	SecretFile    string `json:"SecretFile"`
+	SpreadsheetID string `json:"SpreadsheetID" env:"PMOCLIENT_SPREADSHEET_ID"`
+	SecretFile    string `json:"SecretFile" env:"PMOCLIENT_SPREADSHEET_SECRET_FILE"`
 }
@@ -1,123 +1,253 @@
 package pmo
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"net/http/cookiejar"
 	"net/url"
 	"strings"
 	"time"
 )
 
-// Configuration of PMO client
+// Configuration of PMO client. Every field can be overridden by environment variable from `env` tag.
 type Configuration struct {
-	Username      string               `json:"username"`
-	Password      string               `json:"password"`
-	FilterUsers   []string             `json:"filterUsers"`
-	LoginURL      string               `json:"loginUrl"`
-	PeopleListURL string               `json:"peopleListUrl"`
-	Spreadsheet   EngineersSpreadsheet `json:"Spreadsheet"`
+	Username        string               `json:"username" env:"PMOCLIENT_USERNAME"`
+	Password        string               `json:"password" env:"PMOCLIENT_PASSWORD"`
+	PasswordCommand string               `json:"passwordCommand" env:"PMOCLIENT_PASSWORD_COMMAND"`
+	PasswordFile    string               `json:"passwordFile" env:"PMOCLIENT_PASSWORD_FILE"`
+	FilterUsers     []string             `json:"filterUsers" env:"PMOCLIENT_FILTER_USERS"`
+	LoginURL        string               `json:"loginUrl" env:"PMOCLIENT_LOGIN_URL"`
+	PeopleListURL   string               `json:"peopleListUrl" env:"PMOCLIENT_PEOPLE_LIST_URL"`
+	Where           string               `json:"where" env:"PMOCLIENT_WHERE"`
+	SnapshotDir     string               `json:"snapshotDir" env:"PMOCLIENT_SNAPSHOT_DIR"`
+	CacheTTL        string               `json:"cacheTTL" env:"PMOCLIENT_CACHE_TTL"`
+	Spreadsheet     EngineersSpreadsheet `json:"Spreadsheet"`
+
+	// Aliases map local names used in filters to PMO ID, username or name.
+	// Environment variable is comma-separated list of `alias=target` pairs.
+	Aliases map[string]string `json:"aliases" env:"PMOCLIENT_ALIASES"`
+
+	// Profiles defines named PMO instances. Non-empty profile fields override top-level values.
+	Profiles       map[string]Configuration `json:"profiles" env:"-"`
+	DefaultProfile string                   `json:"defaultProfile" env:"-"`
+	// Name of the selected profile. Empty if config has no profiles.
+	Name string `json:"-" env:"-"`
 }
 
 // PMO representation
 type PMO struct {
-	config Configuration
-	client *http.Client
+	config      Configuration
+	client      *http.Client
+	credentials CredentialsProvider
+	creds       *Credentials // credentials accepted by PMO, reused on re-login
+	cookies     *sessionJar
+	sessionFile string
+	hasSession  bool
 }
 
 // NewPMO returns prepared PMO structure
 func NewPMO(config Configuration) PMO {
 	// initialize http client
-	var cookieJar, _ = cookiejar.New(nil)
+	var cookies = newSessionJar()
 	var client = &http.Client{
 		Timeout: time.Minute * 1,
-		Jar:     cookieJar,
+		Jar:     cookies,
 		// do not follow redirects
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
 	}
 
-	var pmo = PMO{config: config, client: client}
+	var pmo = PMO{config: config, client: client, credentials: CredentialsFromConfig(config), cookies: cookies}
 	return pmo
 }
 
+// SetCredentialsProvider replaces default credentials provider created from configuration
+func (pmo *PMO) SetCredentialsProvider(provider CredentialsProvider) {
+	pmo.credentials = provider
+	pmo.creds = nil
+}
+
 // Login to PMO
-func (pmo *PMO) Login() {
-	config := pmo.config
-	resp, err := pmo.client.PostForm(config.LoginURL, url.Values{"j_username": {config.Username}, "j_password": {config.Password}})
+func (pmo *PMO) Login(ctx context.Context) error {
+	creds := pmo.creds
+	if creds == nil {
+		c, err := pmo.credentials.Credentials(ctx)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrLoginFailed, err)
+		}
+		creds = &c
+	}
+
+	form := url.Values{"j_username": {creds.Username}, "j_password": {creds.Password}}
+	req, err := http.NewRequestWithContext(ctx, "POST", pmo.config.LoginURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("%w: can not create login request: %v", ErrLoginFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := pmo.client.Do(req)
 	if err != nil {
-		log.Fatalf("error during login: %v, resp: %v", err, resp)
+		return fmt.Errorf("%w: %v", ErrLoginFailed, err)
 	}
+	defer closeBody(resp)
 
-	// print output here for debug
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatalf("error on reading: %v. body: %v", err, body)
+		return fmt.Errorf("%w: error on reading response: %v", ErrLoginFailed, err)
+	}
+	if err := pmo.checkLogin(resp, body); err != nil {
+		return err
+	}
+	pmo.creds = creds
+	pmo.hasSession = true
+	// saved session only speeds up next runs, session stays in memory without it
+	if err := pmo.saveSession(); err != nil {
+		log.Printf("session is not saved: %v", err)
 	}
+	return nil
 }
 
-//send request to url. Handle errors somehow.
-func (pmo *PMO) request(url string) *http.Response {
-	req, err := http.NewRequest("GET", url, nil)
+//send request to url.
+func (pmo *PMO) request(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		log.Fatalf("Error happened %v, req: %v", err, req)
+		return nil, fmt.Errorf("%w: can not create request to %q: %v", ErrRequest, url, err)
 	}
+	req.Header.Set("Accept", "application/json")
 	resp, err := pmo.client.Do(req)
 	if err != nil {
-		log.Fatalf("error on sending request: %v, resp: %v", err, resp)
+		return nil, fmt.Errorf("%w: %v", ErrRequest, err)
 	}
-	return resp
+	return resp, nil
 }
 
-// get list of engineers by sending request to
-func (pmo *PMO) engineers() []Person {
-	resp := pmo.request(pmo.config.PeopleListURL)
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Fatalf("Error when closing: %v\n", err)
-		}
-	}()
+// get sends request to url and returns response body. Returns ErrSessionExpired
+// if PMO asks to login again.
+func (pmo *PMO) get(ctx context.Context, url string) ([]byte, error) {
+	resp, err := pmo.request(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
 
+	if pmo.sessionExpired(resp) {
+		return nil, fmt.Errorf("%w: request to %q completed with status %s", ErrSessionExpired, url, resp.Status)
+	}
 	if resp.StatusCode != http.StatusOK {
-		log.Fatalf("request to %q completed with status %s\n", resp.Request.URL, resp.Status)
+		return nil, fmt.Errorf("%w: request to %q completed with status %s", ErrUnexpectedStatus, url, resp.Status)
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatalf("error on reading: %v. body: %v", err, body)
+		return nil, fmt.Errorf("%w: error on reading: %v", ErrDecode, err)
+	}
+	if isHTML(resp, body) {
+		return nil, fmt.Errorf("%w: request to %q returned HTML page instead of JSON", ErrSessionExpired, url)
+	}
+	// PMO may refresh session cookies on any request
+	if err := pmo.saveSession(); err != nil {
+		log.Printf("session is not saved: %v", err)
+	}
+	return body, nil
+}
+
+// getJSON requests url and decodes JSON response into v. Expired session is
+// renewed with Login and request retried once.
+func (pmo *PMO) getJSON(ctx context.Context, url string, v interface{}) error {
+	body, err := pmo.get(ctx, url)
+	if errors.Is(err, ErrSessionExpired) {
+		if err := pmo.Login(ctx); err != nil {
+			return err
+		}
+		body, err = pmo.get(ctx, url)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%w: %v. body starts with: %q", ErrDecode, err, truncate(string(body), maxBodyInError))
 	}
-	var peopleResponse = new(APIResponse)
-	err = json.Unmarshal(body, &peopleResponse)
+	return nil
+}
+
+// Engineers returns list of all engineers by sending request to PeopleListURL
+func (pmo *PMO) Engineers(ctx context.Context) ([]Person, error) {
+	response, err := pmo.PeopleList(ctx)
 	if err != nil {
-		log.Fatalf("something happened during unmarshall: %v. body: %v\n", err, string(body))
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// PeopleList returns full response of PeopleListURL. Use it to cache or snapshot people list.
+func (pmo *PMO) PeopleList(ctx context.Context) (APIResponse, error) {
+	var peopleResponse APIResponse
+	if err := pmo.getJSON(ctx, pmo.config.PeopleListURL, &peopleResponse); err != nil {
+		return APIResponse{}, err
 	}
-	return peopleResponse.Data
+	return peopleResponse, nil
 }
 
 // FilterEngineers returns only data for subset of engineers defined in `filter``
-func (pmo *PMO) FilterEngineers(filter []string) []Person {
-	// initialize return value
-	filteredEngineers := make([]Person, 0)
+func (pmo *PMO) FilterEngineers(ctx context.Context, filter []string) ([]Person, error) {
+	engineers, err := pmo.Engineers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matched, _ := NewMatcher(engineers, pmo.config.Aliases).Match(filter)
+	return matched, nil
+}
 
-	// initialize temporary map for filtering
-	filterMap := make(map[string]bool)
-	for _, u := range filter {
-		filterMap[strings.Replace(strings.ToLower(u), " ", "", -1)] = true
+// SelectEngineers returns engineers matching predicate
+func (pmo *PMO) SelectEngineers(ctx context.Context, predicate Predicate) ([]Person, error) {
+	engineers, err := pmo.Engineers(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return Select(engineers, predicate), nil
+}
+
+// FilterEngineersByConfig using filter defined in config
+func (pmo *PMO) FilterEngineersByConfig(ctx context.Context) ([]Person, error) {
+	return pmo.FilterEngineers(ctx, pmo.config.FilterUsers)
+}
+
+// FindByName returns engineers with the name, ID or username. See Matcher for details.
+func FindByName(engineers []Person, name string, aliases map[string]string) []Person {
+	return NewMatcher(engineers, aliases).Find(name)
+}
 
-	for _, val := range pmo.engineers() {
-		targetKey := strings.Replace(strings.ToLower(val.Name), " ", "", -1)
-		if filterMap[targetKey] {
-			filteredEngineers = append(filteredEngineers, val)
+// maxBodyInError is how many bytes of response body are shown in decode errors.
+// People list is large and contains personal data, so it is never shown in full.
+const maxBodyInError = 200
+
+// truncate returns at most n first bytes of s with ellipsis if s is longer
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// InvalidAssignments counts assignments with dates or involvement in unknown format
+func InvalidAssignments(engineers []Person) int {
+	count := 0
+	for _, engineer := range engineers {
+		for _, a := range engineer.Assignments {
+			if a.HasInvalidDates() || a.HasInvalidInvolvement() {
+				count++
+			}
 		}
 	}
-	return filteredEngineers
+	return count
 }
 
-// FilterEngineersByConfig using filter defined in config
-func (pmo *PMO) FilterEngineersByConfig() []Person {
-	return pmo.FilterEngineers(pmo.config.FilterUsers)
+// closeBody closes response body. Errors are ignored: body is already consumed at this point.
+func closeBody(resp *http.Response) {
+	_ = resp.Body.Close()
 }
@@ -0,0 +1,48 @@
+package pmo
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// GroupBy groups engineers by keys returned by key function.
+// Engineer is added to every group returned by keys.
+func GroupBy(engineers []Person, keys func(Person) []string) map[string][]Person {
+	groups := map[string][]Person{}
+	for _, engineer := range engineers {
+		for _, key := range keys(engineer) {
+			groups[key] = append(groups[key], engineer)
+		}
+	}
+	return groups
+}
+
+// PrintGroups prints groups sorted by name with number of engineers and their names
+func PrintGroups(w io.Writer, title string, groups map[string][]Person) error {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 5, 0, 1, ' ', 0)
+	if _, err := fmt.Fprintf(tw, "%s\tEngineers\tNames\n", title); err != nil {
+		return fmt.Errorf("%w: failed to print header: %v", ErrOutput, err)
+	}
+	for _, name := range names {
+		engineers := make([]string, 0, len(groups[name]))
+		for _, engineer := range groups[name] {
+			engineers = append(engineers, engineer.Name)
+		}
+		if _, err := fmt.Fprintf(tw, "%s\t%d\t%s\n", name, len(engineers), strings.Join(engineers, ", ")); err != nil {
+			return fmt.Errorf("%w: failed to print group %v: %v", ErrOutput, name, err)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("%w: can not flush tabwriter: %v", ErrOutput, err)
+	}
+	return nil
+}
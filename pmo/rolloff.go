@@ -0,0 +1,116 @@
+package pmo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// oneDay is duration of calendar day
+const oneDay = 24 * time.Hour
+
+// Rolloff is engineer whose assignment ends soon with nothing lined up right after it
+type Rolloff struct {
+	Name          string      `json:"name"`
+	Username      string      `json:"username"`
+	Location      string      `json:"location"`
+	Grade         string      `json:"grade"`
+	Profile       string      `json:"profile"`
+	AvailableDays int         `json:"availableDays"`
+	Source        string      `json:"source,omitempty"`
+	Ending        Assignment  `json:"ending"`
+	Next          *Assignment `json:"next"`
+	// GapDays is number of days without assignment between ending and next assignments. Nil if there is no next assignment.
+	GapDays *int `json:"gapDays"`
+}
+
+// ParsePeriod parses number of days like `30d`, weeks like `2w` or Go duration like `72h`
+func ParsePeriod(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range map[string]time.Duration{"d": oneDay, "w": 7 * oneDay} {
+		if strings.HasSuffix(value, suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(value, suffix))
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("%w: invalid period %q", ErrInvalidOption, value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%w: invalid period %q, use e.g. 30d, 2w or 72h", ErrInvalidOption, value)
+	}
+	return d, nil
+}
+
+// Rolloffs returns engineers whose assignments active on date end within d after it,
+// if the day after the end is not covered by other assignment. Sorted by end date and name.
+func Rolloffs(engineers []Person, date time.Time, d time.Duration) []Rolloff {
+	var result []Rolloff
+	for _, engineer := range engineers {
+		for _, ending := range engineer.ActiveAssignments(date) {
+			if !ending.EndsWithin(date, d) || engineer.busyOn(ending.Finish.Add(oneDay)) {
+				continue
+			}
+			r := Rolloff{
+				Name:          engineer.Name,
+				Username:      engineer.Username,
+				Location:      engineer.Location,
+				Grade:         engineer.Grade,
+				Profile:       engineer.Profile,
+				AvailableDays: engineer.AvailableDays,
+				Source:        engineer.Source,
+				Ending:        ending,
+			}
+			if next := engineer.nextAssignment(ending.Finish); next != nil {
+				gap := int(next.Start.Sub(ending.Finish)/oneDay) - 1
+				r.Next, r.GapDays = next, &gap
+			}
+			result = append(result, r)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].Ending.Finish.Equal(result[j].Ending.Finish) {
+			return result[i].Ending.Finish.Before(result[j].Ending.Finish)
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// busyOn reports whether engineer has assignment active on date
+func (p *Person) busyOn(date time.Time) bool {
+	return len(p.ActiveAssignments(date)) > 0
+}
+
+// nextAssignment returns the earliest assignment starting after date
+func (p *Person) nextAssignment(date time.Time) *Assignment {
+	var next *Assignment
+	for i := range p.Assignments {
+		a := p.Assignments[i]
+		if a.Start.After(date) && (next == nil || a.Start.Before(next.Start)) {
+			next = &a
+		}
+	}
+	return next
+}
+
+// RolloffTable returns rolloffs as table
+func RolloffTable(rolloffs []Rolloff) Table {
+	table := Table{Header: []string{"Name", "Location", "Grade", "Profile", "Account", "Project", "Finish",
+		"AvailableDays", "NextAccount", "NextProject", "NextStart", "GapDays"}}
+	sources := make([]string, 0, len(rolloffs))
+	for _, r := range rolloffs {
+		row := []interface{}{r.Name, r.Location, r.Grade, r.Profile, r.Ending.Account, r.Ending.Project,
+			FormatDate(r.Ending.Finish), r.AvailableDays, "", "", "", ""}
+		if r.Next != nil {
+			row[8], row[9], row[10], row[11] = r.Next.Account, r.Next.Project, FormatDate(r.Next.Start), *r.GapDays
+		}
+		table.Rows = append(table.Rows, row)
+		sources = append(sources, r.Source)
+	}
+	table.addSourceColumn(sources)
+	return table
+}
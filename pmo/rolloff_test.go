@@ -0,0 +1,99 @@
+package pmo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * oneDay, false},
+		{" 2w ", 14 * oneDay, false},
+		{"0d", 0, false},
+		{"72h", 72 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"d", 0, true},
+		{"-1d", 0, true},
+		{"-72h", 0, true},
+		{"1.5d", 0, true},
+		{"month", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParsePeriod(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidOption) {
+					t.Errorf("ParsePeriod(%q) = %v, %v, want ErrInvalidOption", tt.value, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParsePeriod(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRolloffs(t *testing.T) {
+	engineers := []Person{
+		{Name: "Ivan", Assignments: []Assignment{assignment(11, "2026-01-01", "2026-11-10", 100)}},
+		// next assignment starts right after the end
+		{Name: "Oleksii", Assignments: []Assignment{
+			assignment(21, "2026-01-01", "2026-10-31", 100),
+			assignment(22, "2026-11-01", "", 100),
+		}},
+		{Name: "Anna", Assignments: []Assignment{
+			assignment(31, "2026-01-01", "2026-10-25", 50),
+			assignment(32, "2026-03-01", "2026-12-31", 50),
+			assignment(33, "2027-01-15", "", 100),
+		}},
+		{Name: "Mykola", Assignments: []Assignment{
+			assignment(41, "2026-01-01", "2026-10-25", 100),
+			assignment(42, "2026-11-05", "", 100),
+			assignment(43, "2026-12-01", "", 100),
+		}},
+		// partial assignment ends, but the open one covers the next day; another is already finished
+		{Name: "Zoe", Assignments: []Assignment{
+			assignment(51, "2026-01-01", "2026-12-31", 50),
+			assignment(52, "2026-01-01", "", 50),
+			assignment(53, "2026-01-01", "2026-10-17", 100),
+		}},
+	}
+	type rolloff struct {
+		name    string
+		ending  int
+		next    int
+		gapDays int
+	}
+	tests := []struct {
+		name   string
+		within time.Duration
+		want   []rolloff
+	}{
+		{"month", 30 * oneDay, []rolloff{{"Mykola", 41, 42, 10}, {"Ivan", 11, 0, -1}}},
+		{"week", 7 * oneDay, []rolloff{{"Mykola", 41, 42, 10}}},
+		{"today only", 0, nil},
+		{"quarter", 90 * oneDay, []rolloff{{"Mykola", 41, 42, 10}, {"Ivan", 11, 0, -1}, {"Anna", 32, 33, 14}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []rolloff
+			for _, r := range Rolloffs(engineers, date(2026, 10, 18), tt.within) {
+				got = append(got, rolloff{r.Name, r.Ending.ID, 0, -1})
+				if r.Next != nil {
+					got[len(got)-1].next, got[len(got)-1].gapDays = r.Next.ID, *r.GapDays
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rolloffs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package pmo
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// checkLogin verifies response to the login form. PMO answers to successful login
+// with redirect to the application and sets session cookie. Failed login redirects
+// back to the login page or shows login form again.
+func (pmo *PMO) checkLogin(resp *http.Response, body []byte) error {
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: credentials rejected with status %s", ErrLoginFailed, resp.Status)
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		location, err := resp.Location()
+		if err != nil {
+			return fmt.Errorf("%w: redirect without location: %v", ErrLoginFailed, err)
+		}
+		if pmo.isLoginPage(location) {
+			return fmt.Errorf("%w: redirected back to login page %q", ErrLoginFailed, location)
+		}
+	case resp.StatusCode == http.StatusOK:
+		if bytes.Contains(body, []byte("j_password")) {
+			return fmt.Errorf("%w: login form returned again", ErrLoginFailed)
+		}
+	default:
+		return fmt.Errorf("%w: login completed with status %s", ErrLoginFailed, resp.Status)
+	}
+
+	loginURL, err := url.Parse(pmo.config.LoginURL)
+	if err != nil {
+		return fmt.Errorf("%w: can not parse login url: %v", ErrLoginFailed, err)
+	}
+	if len(resp.Cookies()) == 0 && len(pmo.client.Jar.Cookies(loginURL)) == 0 {
+		return fmt.Errorf("%w: no session cookie set", ErrLoginFailed)
+	}
+	return nil
+}
+
+// isLoginPage reports whether u points to the PMO login page: it has the same host and path as LoginURL.
+// Query is ignored, PMO adds error flags to it.
+func (pmo *PMO) isLoginPage(u *url.URL) bool {
+	loginURL, err := url.Parse(pmo.config.LoginURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, loginURL.Host) &&
+		strings.TrimSuffix(u.Path, "/") == strings.TrimSuffix(loginURL.Path, "/")
+}
+
+// sessionExpired reports whether response means session is not valid anymore:
+// PMO redirects to the login page or answers with 401.
+func (pmo *PMO) sessionExpired(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		location, err := resp.Location()
+		return err == nil && pmo.isLoginPage(location)
+	}
+	return false
+}
+
+// isHTML reports whether response contains HTML page instead of expected JSON.
+func isHTML(resp *http.Response, body []byte) bool {
+	if strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		return true
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(body), []byte("<"))
+}
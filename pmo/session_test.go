@@ -0,0 +1,147 @@
+package pmo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestIsLoginPage(t *testing.T) {
+	p := NewPMO(Configuration{LoginURL: "https://pmo.example.com/app/login"})
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://pmo.example.com/app/login", true},
+		{"https://PMO.example.com/app/login/", true},
+		{"https://pmo.example.com/app/login?error=1", true},
+		{"https://pmo.example.com/app/loginHelp", false},
+		{"https://pmo.example.com/blog/login-tips", false},
+		{"https://sso.example.com/app/login", false},
+		{"https://pmo.example.com/app/home", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.isLoginPage(u); got != tt.want {
+				t.Errorf("isLoginPage(%s) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+// pmoServer is fake PMO: people list is returned to requests with session cookie,
+// other requests are redirected to the login page
+type pmoServer struct {
+	*httptest.Server
+	// login answers to login form
+	login  http.HandlerFunc
+	logins int
+}
+
+func newPMOServer(login http.HandlerFunc) *pmoServer {
+	s := &pmoServer{login: login}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		s.logins++
+		s.login(w, r)
+	})
+	mux.HandleFunc("/api/people", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session"); err != nil || c.Value != "valid" {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"id":1,"name":"Ivan Petrenko"}]}`))
+	})
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// acceptLogin sets session cookie and redirects to the application
+func acceptLogin(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: "session", Value: "valid", Path: "/"})
+	http.Redirect(w, r, "/app", http.StatusFound)
+}
+
+func (s *pmoServer) config() Configuration {
+	return Configuration{Username: "user", Password: "secret",
+		LoginURL: s.URL + "/login", PeopleListURL: s.URL + "/api/people"}
+}
+
+func TestEngineersRenewsExpiredSession(t *testing.T) {
+	server := newPMOServer(acceptLogin)
+	defer server.Close()
+
+	p := NewPMO(server.config())
+	u, _ := url.Parse(server.URL)
+	p.client.Jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: "expired", Path: "/"}})
+
+	engineers, err := p.Engineers(context.Background())
+	if err != nil {
+		t.Fatalf("Engineers returned error: %v", err)
+	}
+	if len(engineers) != 1 || engineers[0].Name != "Ivan Petrenko" {
+		t.Errorf("Engineers = %+v", engineers)
+	}
+	if server.logins != 1 {
+		t.Errorf("logged in %d times, want 1", server.logins)
+	}
+}
+
+func TestLoginRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		login http.HandlerFunc
+	}{
+		{"redirect to login page", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/login?error=true", http.StatusFound)
+		}},
+		{"unauthorized", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}},
+		{"login form again", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<form><input name="j_username"><input name="j_password"></form>`))
+		}},
+		{"no session cookie", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/app", http.StatusFound)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPMOServer(tt.login)
+			defer server.Close()
+
+			p := NewPMO(server.config())
+			if err := p.Login(context.Background()); !errors.Is(err, ErrLoginFailed) {
+				t.Errorf("Login returned %v, want ErrLoginFailed", err)
+			}
+			_, err := p.Engineers(context.Background())
+			if !errors.Is(err, ErrLoginFailed) {
+				t.Errorf("Engineers returned %v, want ErrLoginFailed", err)
+			}
+			if server.logins != 2 {
+				t.Errorf("logged in %d times, want 2: once by Login and once on expired session", server.logins)
+			}
+		})
+	}
+}
+
+func TestLoginRedirectToPageNamedLogin(t *testing.T) {
+	server := newPMOServer(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "valid", Path: "/"})
+		http.Redirect(w, r, "/app/loginHistory", http.StatusFound)
+	})
+	defer server.Close()
+
+	p := NewPMO(server.config())
+	if err := p.Login(context.Background()); err != nil {
+		t.Errorf("Login returned %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package pmo
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// snapshotExt is extension of snapshot files: gzipped JSON
+	snapshotExt = ".json.gz"
+	// snapshotTimeLayout is layout of snapshot time in snapshot ID
+	snapshotTimeLayout = "20060102T150405Z"
+	// DefaultProfileName is profile name of snapshots taken without config profiles
+	DefaultProfileName = "default"
+	// LatestSnapshot refers to the latest snapshot of profile
+	LatestSnapshot = "latest"
+)
+
+// Snapshot is PMO response saved at some moment
+type Snapshot struct {
+	Time     time.Time   `json:"time"`
+	Profile  string      `json:"profile"`
+	Response APIResponse `json:"response"`
+}
+
+// SnapshotInfo describes snapshot saved in store
+type SnapshotInfo struct {
+	// ID is `<time>_<profile>`, e.g. 20261018T101500Z_prod
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Profile string    `json:"profile"`
+	Size    int64     `json:"size"`
+}
+
+// SnapshotStore keeps snapshots as gzipped JSON files in a directory
+type SnapshotStore struct {
+	dir string
+}
+
+// NewSnapshotStore returns store keeping snapshots in dir. Empty dir means DefaultSnapshotDir.
+func NewSnapshotStore(dir string) (*SnapshotStore, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultSnapshotDir(); err != nil {
+			return nil, err
+		}
+	}
+	return &SnapshotStore{dir: dir}, nil
+}
+
+// DefaultSnapshotDir returns $XDG_DATA_HOME/pmoclient/snapshots or ~/.local/share/pmoclient/snapshots
+func DefaultSnapshotDir() (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("%w: can not detect home directory: %v", ErrConfig, err)
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "pmoclient", "snapshots"), nil
+}
+
+// Dir returns directory of the store
+func (s *SnapshotStore) Dir() string {
+	return s.dir
+}
+
+// SnapshotProfile returns profile name used in snapshots: DefaultProfileName for config without profiles,
+// otherwise profile name safe to use in file names
+func SnapshotProfile(profile string) string {
+	if profile == "" {
+		return DefaultProfileName
+	}
+	return safeFileName(profile)
+}
+
+// Save saves response of profile fetched at t
+func (s *SnapshotStore) Save(profile string, response APIResponse, t time.Time) (SnapshotInfo, error) {
+	snapshot := Snapshot{Time: t.UTC().Truncate(time.Second), Profile: SnapshotProfile(profile), Response: response}
+	id := snapshot.Time.Format(snapshotTimeLayout) + "_" + snapshot.Profile
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return SnapshotInfo{}, fmt.Errorf("%w: can not create snapshot directory: %v", ErrSnapshot, err)
+	}
+
+	path := filepath.Join(s.dir, id+snapshotExt)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return SnapshotInfo{}, fmt.Errorf("%w: can not create snapshot: %v", ErrSnapshot, err)
+	}
+	zw := gzip.NewWriter(f)
+	err = json.NewEncoder(zw).Encode(snapshot)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return SnapshotInfo{}, fmt.Errorf("%w: can not write snapshot %s: %v", ErrSnapshot, id, err)
+	}
+	return s.info(id)
+}
+
+// List returns snapshots of profile sorted by time, oldest first. Empty profile means all profiles.
+func (s *SnapshotStore) List(profile string) ([]SnapshotInfo, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"+snapshotExt))
+	if err != nil {
+		return nil, fmt.Errorf("%w: can not list snapshots: %v", ErrSnapshot, err)
+	}
+	snapshots := []SnapshotInfo{}
+	for _, file := range files {
+		info, err := s.info(strings.TrimSuffix(filepath.Base(file), snapshotExt))
+		if err != nil {
+			continue // not a snapshot
+		}
+		if profile == "" || info.Profile == profile {
+			snapshots = append(snapshots, info)
+		}
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].Time.Before(snapshots[j].Time) })
+	return snapshots, nil
+}
+
+// info returns info of snapshot with id
+func (s *SnapshotStore) info(id string) (SnapshotInfo, error) {
+	parts := strings.SplitN(id, "_", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return SnapshotInfo{}, fmt.Errorf("%w: invalid snapshot ID %q", ErrSnapshot, id)
+	}
+	t, err := time.Parse(snapshotTimeLayout, parts[0])
+	if err != nil {
+		return SnapshotInfo{}, fmt.Errorf("%w: invalid snapshot ID %q", ErrSnapshot, id)
+	}
+	stat, err := os.Stat(filepath.Join(s.dir, id+snapshotExt))
+	if err != nil {
+		return SnapshotInfo{}, fmt.Errorf("%w: snapshot %s not found", ErrSnapshot, id)
+	}
+	return SnapshotInfo{ID: id, Time: t, Profile: parts[1], Size: stat.Size()}, nil
+}
+
+// Find returns snapshot of profile referenced by ref: snapshot ID, `latest` or date in DateLayout
+// for the latest snapshot taken on or before the date. Snapshot ID of another profile is an error.
+func (s *SnapshotStore) Find(ref string, profile string) (SnapshotInfo, error) {
+	profile = SnapshotProfile(profile)
+	if info, err := s.info(ref); err == nil {
+		if info.Profile != profile {
+			return SnapshotInfo{}, fmt.Errorf("%w: snapshot %s is taken from profile %q, not %q",
+				ErrSnapshot, ref, info.Profile, profile)
+		}
+		return info, nil
+	}
+	snapshots, err := s.List(profile)
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+
+	until := time.Now()
+	if ref != LatestSnapshot {
+		date, err := time.Parse(DateLayout, ref)
+		if err != nil {
+			return SnapshotInfo{}, fmt.Errorf("%w: snapshot %q not found, use snapshot ID, %q or date in YYYY-MM-DD format",
+				ErrSnapshot, ref, LatestSnapshot)
+		}
+		until = date.AddDate(0, 0, 1)
+	}
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].Time.Before(until) {
+			return snapshots[i], nil
+		}
+	}
+	return SnapshotInfo{}, fmt.Errorf("%w: no snapshot of profile %q for %q", ErrSnapshot, profile, ref)
+}
+
+// Load reads snapshot with id
+func (s *SnapshotStore) Load(id string) (Snapshot, error) {
+	path := filepath.Join(s.dir, id+snapshotExt)
+	f, err := os.Open(path) // nolint: gosec
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%w: can not open snapshot %s: %v", ErrSnapshot, id, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%w: can not read snapshot %s: %v", ErrSnapshot, id, err)
+	}
+	raw, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%w: can not read snapshot %s: %v", ErrSnapshot, id, err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("%w: can not decode snapshot %s: %v", ErrSnapshot, id, err)
+	}
+	return snapshot, nil
+}
+
+// Prune removes snapshots of profile taken before `before` keeping at least `keep` latest snapshots of
+// every profile. Zero before means any time. Empty profile means all profiles. Returns removed snapshots.
+func (s *SnapshotStore) Prune(profile string, keep int, before time.Time) ([]SnapshotInfo, error) {
+	snapshots, err := s.List(profile)
+	if err != nil {
+		return nil, err
+	}
+	kept := map[string]int{}
+	removed := []SnapshotInfo{}
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		snapshot := snapshots[i]
+		if kept[snapshot.Profile] < keep || (!before.IsZero() && !snapshot.Time.Before(before)) {
+			kept[snapshot.Profile]++
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, snapshot.ID+snapshotExt)); err != nil {
+			return removed, fmt.Errorf("%w: can not remove snapshot %s: %v", ErrSnapshot, snapshot.ID, err)
+		}
+		removed = append(removed, snapshot)
+	}
+	return removed, nil
+}
@@ -0,0 +1,145 @@
+package pmo
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// snapshotStore returns store in temporary directory with snapshots of profiles taken at times.
+// Caller removes the directory.
+func snapshotStore(t *testing.T, snapshots map[string][]time.Time) *SnapshotStore {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "pmoclient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewSnapshotStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for profile, times := range snapshots {
+		for _, at := range times {
+			if _, err := store.Save(profile, APIResponse{}, at); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return store
+}
+
+// snapshotIDs returns IDs of snapshots
+func snapshotIDs(snapshots []SnapshotInfo) []string {
+	ids := []string{}
+	for _, s := range snapshots {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func TestSnapshotStoreFind(t *testing.T) {
+	store := snapshotStore(t, map[string][]time.Time{
+		"":     {time.Date(2026, 10, 11, 7, 0, 0, 0, time.UTC), time.Date(2026, 10, 18, 7, 0, 0, 0, time.UTC)},
+		"prod": {time.Date(2026, 10, 12, 23, 59, 59, 0, time.UTC), time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC)},
+	})
+	defer func() { _ = os.RemoveAll(store.Dir()) }()
+	tests := []struct {
+		ref, profile string
+		want         string
+	}{
+		{"latest", "", "20261018T070000Z_default"},
+		{"latest", "prod", "20261013T000000Z_prod"},
+		{"2026-10-17", "", "20261011T070000Z_default"},
+		{"2026-10-18", "", "20261018T070000Z_default"},
+		{"2026-10-12", "prod", "20261012T235959Z_prod"},
+		{"20261011T070000Z_default", "", "20261011T070000Z_default"},
+		{"20261012T235959Z_prod", "prod", "20261012T235959Z_prod"},
+		// snapshot ID of another profile
+		{"20261011T070000Z_default", "prod", ""},
+		{"20261012T235959Z_prod", "", ""},
+		{"2026-10-10", "", ""},
+		{"latest", "staging", ""},
+		{"yesterday", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref+"/"+tt.profile, func(t *testing.T) {
+			info, err := store.Find(tt.ref, tt.profile)
+			if tt.want == "" {
+				if !errors.Is(err, ErrSnapshot) {
+					t.Errorf("Find(%q, %q) = %v, %v, want ErrSnapshot", tt.ref, tt.profile, info.ID, err)
+				}
+				return
+			}
+			if err != nil || info.ID != tt.want {
+				t.Errorf("Find(%q, %q) = %v, %v, want %v", tt.ref, tt.profile, info.ID, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotStorePrune(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 7, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name    string
+		profile string
+		keep    int
+		before  time.Time
+		want    []string
+	}{
+		// removed latest first, snapshots taken at the same time are in reverse order of profiles
+		{"keep latest of every profile", "", 1, time.Time{}, []string{
+			"20261012T070000Z_default", "20261011T070000Z_prod", "20261011T070000Z_default"}},
+		{"keep two of profile", "prod", 2, time.Time{}, []string{}},
+		{"before date", "", 0, day(12), []string{"20261011T070000Z_prod", "20261011T070000Z_default"}},
+		{"before date keeping latest", "", 1, day(13), []string{
+			"20261012T070000Z_default", "20261011T070000Z_prod", "20261011T070000Z_default"}},
+		{"profile only", "default", 0, time.Time{}, []string{
+			"20261013T070000Z_default", "20261012T070000Z_default", "20261011T070000Z_default"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := snapshotStore(t, map[string][]time.Time{
+				"":     {day(11), day(12), day(13)},
+				"prod": {day(11), day(14)},
+			})
+			defer func() { _ = os.RemoveAll(store.Dir()) }()
+			removed, err := store.Prune(tt.profile, tt.keep, tt.before)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := snapshotIDs(removed); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("removed %v, want %v", got, tt.want)
+			}
+			left, err := store.List("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(left)+len(removed) != 5 {
+				t.Errorf("%d snapshots left after removing %d of 5", len(left), len(removed))
+			}
+		})
+	}
+}
+
+func TestSnapshotProfile(t *testing.T) {
+	tests := []struct {
+		profile, want string
+	}{
+		{"", DefaultProfileName},
+		{"prod", "prod"},
+		{"eu_west-1.staging", "eu_west-1.staging"},
+		{"../../etc", "..-..-etc"},
+		{"my team/prod", "my-team-prod"},
+		{"київ", "----"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			if got := SnapshotProfile(tt.profile); got != tt.want {
+				t.Errorf("SnapshotProfile(%q) = %q, want %q", tt.profile, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package pmo
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateFuncs returns functions available in templates executed by WriteTemplate
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"accounts":    func(p Person) []string { return p.GetAccounts() },
+		"projects":    func(p Person) []string { return p.GetProjects() },
+		"statuses":    func(p Person) []string { return RemoveDuplicates(p.AssignmentStatuses()) },
+		"managers":    func(p Person) []string { return RemoveDuplicates(p.GetEngineerManagers()) },
+		"involvement": func(p Person) float64 { return p.TotalInvolvement() },
+		"totalInvolvement": func(engineers []Person) float64 {
+			total := 0.0
+			for i := range engineers {
+				total += engineers[i].TotalInvolvement()
+			}
+			return total
+		},
+		"join":  func(sep string, elements []string) string { return strings.Join(elements, sep) },
+		"date":  formatDate,
+		"now":   time.Now,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"default": func(def string, value string) string {
+			if value == "" {
+				return def
+			}
+			return value
+		},
+	}
+}
+
+// formatDate formats date with Go layout, e.g. `{{ date "Jan 2" .Finish }}`.
+// Value may be time.Time or string in one of dateLayouts. Unknown strings are returned as is.
+func formatDate(layout string, value interface{}) string {
+	switch value := value.(type) {
+	case time.Time:
+		return value.Format(layout)
+	case string:
+		if t, err := ParseDate(value); err == nil {
+			return t.Format(layout)
+		}
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// ParseTemplate parses report template with TemplateFuncs
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("report").Funcs(TemplateFuncs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: can not parse template: %v", ErrInvalidOption, err)
+	}
+	return tmpl, nil
+}
+
+// WriteTemplate executes template against engineers and writes result to w
+func WriteTemplate(w io.Writer, engineers []Person, tmpl *template.Template) error {
+	if err := tmpl.Execute(w, nonNil(engineers)); err != nil {
+		return fmt.Errorf("%w: can not execute template: %v", ErrOutput, err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package pmo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValidationError lists all problems found in configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v:\n  - %s", ErrConfig, strings.Join(e.Problems, "\n  - "))
+}
+
+// Is makes ValidationError match ErrConfig
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrConfig
+}
+
+// problemsError returns ValidationError with problems or nil if there are no problems
+func problemsError(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// Validate checks that required fields are set and have proper format.
+// Spreadsheet settings are checked only if spreadsheet is going to be used.
+func (config Configuration) Validate(spreadsheet bool) error {
+	var problems []string
+	prefix := ""
+	if config.Name != "" {
+		prefix = "profile " + config.Name + ": "
+	}
+
+	for _, u := range []struct{ name, value string }{
+		{"loginUrl", config.LoginURL},
+		{"peopleListUrl", config.PeopleListURL},
+	} {
+		if problem := checkURL(u.name, u.value); problem != "" {
+			problems = append(problems, prefix+problem)
+		}
+	}
+
+	if config.Where != "" {
+		if _, err := ParseFilter(config.Where); err != nil {
+			problems = append(problems, prefix+fmt.Sprintf("where: %v", err))
+		}
+	}
+
+	if config.CacheTTL != "" {
+		if _, err := ParsePeriod(config.CacheTTL); err != nil {
+			problems = append(problems, prefix+fmt.Sprintf("cacheTTL: %v", err))
+		}
+	}
+
+	if config.PasswordFile != "" {
+		if _, err := os.Stat(config.PasswordFile); err != nil {
+			problems = append(problems, prefix+fmt.Sprintf("passwordFile: %v", err))
+		}
+	}
+
+	if spreadsheet {
+		if config.Spreadsheet.SpreadsheetID == "" {
+			problems = append(problems, prefix+"Spreadsheet.SpreadsheetID is required to use spreadsheet")
+		}
+		if config.Spreadsheet.SecretFile == "" {
+			problems = append(problems, prefix+"Spreadsheet.SecretFile is required to use spreadsheet")
+		} else if _, err := os.Stat(config.Spreadsheet.SecretFile); err != nil {
+			problems = append(problems, prefix+fmt.Sprintf("Spreadsheet.SecretFile: %v", err))
+		}
+	}
+	return problemsError(problems)
+}
+
+// checkURL returns problem description if value is not absolute http(s) URL
+func checkURL(name string, value string) string {
+	if value == "" {
+		return name + " is required"
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Sprintf("%s %q is not valid URL: %v", name, value, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Sprintf("%s %q must be absolute http or https URL", name, value)
+	}
+	return ""
+}
+
+// ValidateConfig checks config file and all its profiles and reports every problem at once.
+// Warnings are returned for issues which do not prevent tool from working.
+func ValidateConfig(path string, spreadsheet bool) ([]string, error) {
+	raw, configPath, err := readRawConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, problemsError([]string{"config file not found: " + configPath})
+	}
+
+	problems := unknownFields(raw, reflect.TypeOf(Configuration{}), "", false)
+	var file Configuration
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, problemsError(append(problems, fmt.Sprintf("can not decode %v: %v", configPath, err)))
+	}
+
+	var warnings []string
+	if warning := permissionWarning(configPath, file); warning != "" {
+		warnings = append(warnings, warning)
+	}
+
+	names := file.ProfileNames()
+	if len(names) == 0 {
+		names = []string{""}
+	}
+	if file.DefaultProfile != "" {
+		if _, ok := file.Profiles[file.DefaultProfile]; !ok {
+			problems = append(problems, fmt.Sprintf("defaultProfile %q is not defined in profiles", file.DefaultProfile))
+		}
+	}
+	for _, name := range names {
+		config, _, err := selectProfile(file, "file "+configPath, name)
+		if err == nil {
+			err = config.Validate(spreadsheet)
+		}
+		if vErr, ok := err.(*ValidationError); ok {
+			problems = append(problems, vErr.Problems...)
+		} else if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return warnings, problemsError(problems)
+}
+
+// unknownFields returns descriptions of keys in raw JSON object which do not match
+// fields of type t exactly. Profiles can not be nested.
+func unknownFields(raw []byte, t reflect.Type, prefix string, inProfile bool) []string {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil // type errors are reported by decoder
+	}
+
+	known := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			known[name] = field
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		field, ok := known[key]
+		switch {
+		case !ok:
+			problem := fmt.Sprintf("unknown field %q", prefix+key)
+			for name := range known {
+				if strings.EqualFold(name, key) {
+					problem += fmt.Sprintf(", did you mean %q?", prefix+name)
+				}
+			}
+			problems = append(problems, problem)
+		case inProfile && (field.Name == "Profiles" || field.Name == "DefaultProfile"):
+			problems = append(problems, fmt.Sprintf("field %q is not allowed inside profile", prefix+key))
+		case field.Type.Kind() == reflect.Struct:
+			problems = append(problems, unknownFields(object[key], field.Type, prefix+key+".", inProfile)...)
+		case field.Name == "Profiles":
+			var profiles map[string]json.RawMessage
+			if err := json.Unmarshal(object[key], &profiles); err != nil {
+				continue
+			}
+			names := make([]string, 0, len(profiles))
+			for name := range profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				problems = append(problems, unknownFields(profiles[name], t, prefix+key+"."+name+".", true)...)
+			}
+		}
+	}
+	return problems
+}
+
+// permissionWarning returns warning if config file containing password is readable by others
+func permissionWarning(path string, config Configuration) string {
+	hasPassword := config.Password != ""
+	for _, profile := range config.Profiles {
+		hasPassword = hasPassword || profile.Password != ""
+	}
+	if !hasPassword {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0077 == 0 {
+		return ""
+	}
+	return fmt.Sprintf("config file %v contains password and is accessible by group or others (mode %v). Run chmod 600 %v",
+		path, info.Mode().Perm(), path)
+}
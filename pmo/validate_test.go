@@ -0,0 +1,139 @@
+package pmo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnknownFields(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{"known", `{"username": "u", "Spreadsheet": {"SecretFile": "s"}, "profiles": {"prod": {"loginUrl": "x"}}}`, nil},
+		{"typo", `{"userName": "u", "filterUser": []}`, []string{
+			`unknown field "filterUser"`,
+			`unknown field "userName", did you mean "username"?`,
+		}},
+		{"nested", `{"Spreadsheet": {"secretFile": "s", "Sheet": "x"}}`, []string{
+			`unknown field "Spreadsheet.Sheet"`,
+			`unknown field "Spreadsheet.secretFile", did you mean "Spreadsheet.SecretFile"?`,
+		}},
+		{"in profile", `{"profiles": {"prod": {"loginURL": "x", "profiles": {}, "defaultProfile": "a"}}}`, []string{
+			`field "profiles.prod.defaultProfile" is not allowed inside profile`,
+			`unknown field "profiles.prod.loginURL", did you mean "profiles.prod.loginUrl"?`,
+			`field "profiles.prod.profiles" is not allowed inside profile`,
+		}},
+		{"name is not a field", `{"Name": "prod"}`, []string{`unknown field "Name"`}},
+		{"not an object", `[]`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unknownFields([]byte(tt.raw), reflect.TypeOf(Configuration{}), "", false)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unknownFields = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigurationValidate(t *testing.T) {
+	valid := Configuration{LoginURL: "https://pmo/login", PeopleListURL: "https://pmo/people"}
+	tests := []struct {
+		name        string
+		change      func(c *Configuration)
+		spreadsheet bool
+		want        []string
+	}{
+		{"valid", func(c *Configuration) {}, false, nil},
+		{"missing URLs", func(c *Configuration) { c.LoginURL, c.PeopleListURL = "", "" }, false,
+			[]string{"loginUrl is required", "peopleListUrl is required"}},
+		{"relative URL", func(c *Configuration) { c.LoginURL = "/login" }, false,
+			[]string{`loginUrl "/login" must be absolute http or https URL`}},
+		{"where", func(c *Configuration) { c.Where = "grade ==" }, false, []string{"where: "}},
+		{"cacheTTL", func(c *Configuration) { c.CacheTTL = "soon" }, false, []string{"cacheTTL: "}},
+		{"profile name prefix", func(c *Configuration) { c.Name, c.LoginURL = "prod", "" }, false,
+			[]string{"profile prod: loginUrl is required"}},
+		{"spreadsheet", func(c *Configuration) {}, true, []string{
+			"Spreadsheet.SpreadsheetID is required to use spreadsheet",
+			"Spreadsheet.SecretFile is required to use spreadsheet",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.change(&config)
+			err := config.Validate(tt.spreadsheet)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate returned %v", err)
+				}
+				return
+			}
+			var vErr *ValidationError
+			if !errors.As(err, &vErr) || !errors.Is(err, ErrConfig) {
+				t.Fatalf("Validate returned %v, want ValidationError", err)
+			}
+			if len(vErr.Problems) != len(tt.want) {
+				t.Fatalf("problems %q, want %q", vErr.Problems, tt.want)
+			}
+			for i, problem := range vErr.Problems {
+				if !strings.HasPrefix(problem, tt.want[i]) {
+					t.Errorf("problem %q, want %q", problem, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	dir, remove := configDir(t, map[string]string{
+		"valid.yaml": "loginUrl: https://pmo/login\npeopleListUrl: https://pmo/people\n",
+		"invalid.json": `{
+			"loginUrl": "https://pmo/login",
+			"peopleListUrl": "pmo/people",
+			"usename": "u",
+			"defaultProfile": "qa",
+			"profiles": {"prod": {}, "staging": {"loginUrl": "ftp://pmo-staging/login"}}
+		}`,
+		"password.json": `{"loginUrl": "https://pmo/login", "peopleListUrl": "https://pmo/people", "password": "secret"}`,
+	})
+	defer remove()
+	defer setEnv(t, nil)()
+	if err := os.Chmod(filepath.Join(dir, "password.json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file         string
+		wantProblems int
+		wantWarnings int
+	}{
+		{"valid.yaml", 0, 0},
+		// unknown field, undefined default profile, peopleListUrl of both profiles and loginUrl of staging
+		{"invalid.json", 5, 0},
+		{"password.json", 0, 1},
+		{"missing.json", 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			warnings, err := ValidateConfig(filepath.Join(dir, tt.file), false)
+			var problems []string
+			var vErr *ValidationError
+			if errors.As(err, &vErr) {
+				problems = vErr.Problems
+			} else if err != nil {
+				problems = []string{err.Error()}
+			}
+			if len(problems) != tt.wantProblems || len(warnings) != tt.wantWarnings {
+				t.Errorf("ValidateConfig = %q, %q, want %d problems and %d warnings",
+					warnings, problems, tt.wantProblems, tt.wantWarnings)
+			}
+		})
+	}
+}
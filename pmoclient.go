@@ -3,49 +3,207 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
 
-	"github.com/vistrcm/pmoclient/gdocs"
-	"github.com/vistrcm/pmoclient/pmo"
+// Exit codes
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
 )
 
-// formatString for print results
-const formatString = "%s\t%s\t%s\t%s\t%s\t%s\t%v\n"
-const relativeConfigFilePath = "/.config/pmoclient.json"
+// options are command line options common for all commands
+type options struct {
+	configPath string
+	profile    string
+	profiles   string
+	timeout    time.Duration
+	snapshot   string // run from the snapshot instead of PMO
+	offline    bool   // use only cached people list
+	refresh    bool   // fetch people list even if cache is fresh
+}
+
+// opts are options of the running command
+var opts options
+
+// usageError is returned on wrong command line usage
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+// usageErrorf formats usage error
+func usageErrorf(format string, a ...interface{}) error {
+	return &usageError{message: fmt.Sprintf(format, a...)}
+}
+
+// command is a node of command tree. Leaf commands have run function, command with
+// subcommands may have run function for invocation without subcommand.
+type command struct {
+	name        string
+	args        string // synopsis of positional arguments
+	description string
+	flags       func(fs *flag.FlagSet)
+	run         func(ctx context.Context, args []string) error
+	subcommands []*command
+}
 
 func main() {
-	var config pmo.Configuration
-	var useSpreadSheet = flag.Bool("spreadsheet", false, "use spreadsheet to get names and update spreadsheet at the end")
+	log.SetFlags(0)
+	log.SetPrefix("pmoclient: ")
 
-	flag.Parse()
-	// read config
-	config = pmo.ReadConfig(relativeConfigFilePath)
+	ctx, cancel := signalContext()
+	err := rootCommand().execute(ctx, []string{"pmoclient"}, os.Args[1:])
+	cancel()
+	os.Exit(exitCode(err))
+}
 
-	p := pmo.NewPMO(config)
-	p.Login()
+// exitCode reports error and returns process exit code for it
+func exitCode(err error) int {
+	var usageErr *usageError
+	switch {
+	case err == nil || errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usageErr):
+		log.Println(err)
+		return exitUsage
+	default:
+		log.Println(err)
+		return exitError
+	}
+}
 
-	engineersCh := make(chan []pmo.Person)
-	doneCh := make(chan bool)
+// execute parses flags and runs command or one of its subcommands
+func (c *command) execute(ctx context.Context, path []string, args []string) error {
+	fs := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	commonFlags(fs)
+	if c.flags != nil {
+		c.flags(fs)
+	}
+	fs.Usage = func() { c.usage(fs, path) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{message: err.Error()}
+	}
 
-	go printTable(engineersCh, doneCh)
+	if len(c.subcommands) > 0 && fs.NArg() > 0 {
+		for _, sub := range c.subcommands {
+			if sub.name == fs.Arg(0) {
+				if err := checkOwnFlags(fs, sub.name); err != nil {
+					return err
+				}
+				return sub.execute(ctx, append(path, sub.name), fs.Args()[1:])
+			}
+		}
+		if c.run == nil || c.args == "" {
+			return usageErrorf("unknown command %q. Run '%s -h' for usage", fs.Arg(0), strings.Join(path, " "))
+		}
+	}
+	if c.run == nil {
+		fs.Usage()
+		return usageErrorf("%s requires a command", strings.Join(path, " "))
+	}
+
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	return c.run(ctx, fs.Args())
+}
 
-	var engineers []pmo.Person
-	if !*useSpreadSheet {
-		engineers = p.FilterEngineersByConfig()
-		engineersCh <- engineers
-	} else {
-		es := gdocs.NewEngineersSheet(config.Spreadsheet.SpreadsheetID, config.Spreadsheet.SecretFile)
-		filter := es.GetNames()
-		engineers = p.FilterEngineers(filter)
-		engineersCh <- engineers
-		es.Clear()
-		es.AppendEngineers(engineers)
+// usage prints help for the command
+func (c *command) usage(fs *flag.FlagSet, path []string) {
+	out := fs.Output()
+	name := strings.Join(path, " ")
+	switch {
+	case len(c.subcommands) > 0 && c.run != nil:
+		fmt.Fprintf(out, "Usage: %s [flags] [<command>]\n", name)
+	case len(c.subcommands) > 0:
+		fmt.Fprintf(out, "Usage: %s [flags] <command>\n", name)
+	default:
+		fmt.Fprintf(out, "Usage: %s [flags] %s\n", name, c.args)
+	}
+	fmt.Fprintf(out, "\n%s\n", c.description)
+	if len(c.subcommands) > 0 {
+		fmt.Fprintf(out, "\nCommands:\n")
+		for _, sub := range c.subcommands {
+			fmt.Fprintf(out, "  %-12s %s\n", sub.name, firstSentence(sub.description))
+		}
 	}
-	<-doneCh
+	fmt.Fprintf(out, "\nFlags:\n")
+	fs.PrintDefaults()
+}
+
+// commonFlags registers flags accepted by every command
+func commonFlags(fs *flag.FlagSet) {
+	fs.StringVar(&opts.configPath, "config", opts.configPath, "path to config file. Default is $PMOCLIENT_CONFIG, $XDG_CONFIG_HOME/pmoclient.json or ~/.config/pmoclient.json")
+	fs.StringVar(&opts.profile, "profile", opts.profile, "config profile to use. Default is defaultProfile from config")
+	fs.StringVar(&opts.profiles, "profiles", opts.profiles, "comma-separated list of config profiles to query and merge, or 'all'")
+	fs.DurationVar(&opts.timeout, "timeout", opts.timeout, "overall time limit for the run, e.g. 30s or 2m. 0 means no limit")
+	fs.StringVar(&opts.snapshot, "snapshot", opts.snapshot, "use saved snapshot instead of PMO: snapshot ID, 'latest' "+
+		"or date in YYYY-MM-DD format for the latest snapshot taken on or before the date")
+	fs.BoolVar(&opts.offline, "offline", opts.offline, "use only cached people list of any age, do not connect to PMO")
+	fs.BoolVar(&opts.refresh, "refresh", opts.refresh, "fetch people list from PMO even if cached list is fresh")
 }
 
-func printTable(engineersCh chan []pmo.Person, doneCh chan bool) {
-	engineers := <-engineersCh
-	pmo.PrintTable(engineers, formatString) // print table representation of engineers
-	doneCh <- true
+// checkOwnFlags returns usage error if flags of the command itself are set before subcommand:
+// only common flags are passed down to subcommands, others would be silently ignored
+func checkOwnFlags(fs *flag.FlagSet, sub string) error {
+	common := flag.NewFlagSet("common", flag.ContinueOnError)
+	commonFlags(common)
+	var own []string
+	fs.Visit(func(f *flag.Flag) {
+		if common.Lookup(f.Name) == nil {
+			own = append(own, "-"+f.Name)
+		}
+	})
+	if len(own) == 0 {
+		return nil
+	}
+	return usageErrorf("%s must be given after %q command", strings.Join(own, ", "), sub)
+}
+
+// firstSentence returns first sentence of the text
+func firstSentence(text string) string {
+	if i := strings.Index(text, ". "); i >= 0 {
+		return text[:i+1]
+	}
+	return text
+}
+
+// signalContext returns context cancelled on first SIGINT. Second SIGINT terminates the process.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		select {
+		case <-sigCh:
+			log.Println("interrupted, cancelling. Press Ctrl+C again to exit immediately")
+			cancel()
+		case <-ctx.Done():
+			return
+		}
+		<-sigCh
+		os.Exit(130)
+	}()
+	return ctx, func() {
+		signal.Stop(sigCh)
+		cancel()
+	}
 }